@@ -0,0 +1,343 @@
+package main
+
+import "strings"
+
+// Token kinds of the C-like tokenizer.
+const (
+	clIdent   = iota
+	clLiteral // strings, chars and numbers
+	clPunct
+)
+
+type clToken struct {
+	kind       int
+	text       string
+	start, end int
+	newline    bool // a line break separates the token from the previous one
+}
+
+// cLikeSyntax describes the lexical details in which the C-like languages
+// differ.
+type cLikeSyntax struct {
+	nestedComments bool // block comments nest (Kotlin)
+	textBlocks     bool // """...""" strings (Java, Kotlin, C#)
+	rawTextBlocks  bool // backslashes do not escape in text blocks (Kotlin, C#)
+	dollarHoles    bool // "${...}" templates in every string (Kotlin)
+	csharpStrings  bool // @"..." verbatim and $"...{...}" interpolated strings, @ident
+	preprocessor   bool // "#" lines, continued by a trailing backslash, are skipped (C, C++)
+	rawStrings     bool // R"delim(...)delim" strings and 1'000 digit separators (C++)
+}
+
+// cppRawPrefixes start a C++ raw string literal when a quote follows.
+var cppRawPrefixes = map[string]bool{"R": true, "LR": true, "uR": true, "UR": true, "u8R": true}
+
+func isCLikeIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isCLikeIdentChar(c byte) bool {
+	return isCLikeIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// tokenize splits content into tokens, dropping whitespace and comments.
+func (s *cLikeSyntax) tokenize(content string) []clToken {
+	var tokens []clToken
+	newline := false
+	add := func(kind, start, end int) {
+		tokens = append(tokens, clToken{kind: kind, text: content[start:end], start: start, end: end, newline: newline})
+		newline = false
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			newline = true
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				end = len(content) - i
+			}
+			i += end
+		case strings.HasPrefix(content[i:], "/*"):
+			i = s.skipBlockComment(content, i)
+		case c == '#' && s.preprocessor && (newline || len(tokens) == 0):
+			i = skipPreprocessorLine(content, i)
+		case c == '"':
+			end := s.skipString(content, i, s.dollarHoles, false)
+			add(clLiteral, i, end)
+			i = end
+		case c == '\'':
+			end := skipCLikeChar(content, i)
+			add(clLiteral, i, end)
+			i = end
+		case s.csharpStrings && (c == '@' || c == '$'):
+			// Any mix of "$" and "@" prefixes a C# string, "@" alone an
+			// identifier that may be a keyword
+			j := i
+			for j < len(content) && (content[j] == '@' || content[j] == '$') {
+				j++
+			}
+			prefix := content[i:j]
+			switch {
+			case j < len(content) && content[j] == '"':
+				end := s.skipString(content, j, strings.Contains(prefix, "$"), strings.Contains(prefix, "@"))
+				add(clLiteral, i, end)
+				i = end
+			case prefix == "@" && j < len(content) && isCLikeIdentStart(content[j]):
+				for j < len(content) && isCLikeIdentChar(content[j]) {
+					j++
+				}
+				add(clIdent, i, j)
+				i = j
+			default:
+				add(clPunct, i, i+1)
+				i++
+			}
+		case isCLikeIdentStart(c):
+			start := i
+			for i < len(content) && isCLikeIdentChar(content[i]) {
+				i++
+			}
+			if s.rawStrings && i < len(content) && content[i] == '"' && cppRawPrefixes[content[start:i]] {
+				end := skipRawCppString(content, i)
+				add(clLiteral, start, end)
+				i = end
+				continue
+			}
+			add(clIdent, start, i)
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(content) && (isCLikeIdentChar(content[i]) || s.rawStrings && content[i] == '\'' && i+1 < len(content) && isCLikeIdentChar(content[i+1])) {
+				i++
+			}
+			if i+1 < len(content) && content[i] == '.' && content[i+1] >= '0' && content[i+1] <= '9' {
+				i++
+				for i < len(content) && isCLikeIdentChar(content[i]) {
+					i++
+				}
+			}
+			add(clLiteral, start, i)
+		default:
+			add(clPunct, i, i+1)
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipBlockComment returns the index just past the block comment starting at
+// i.
+func (s *cLikeSyntax) skipBlockComment(content string, i int) int {
+	if !s.nestedComments {
+		if end := strings.Index(content[i+2:], "*/"); end != -1 {
+			return i + 2 + end + 2
+		}
+		return len(content)
+	}
+	depth := 0
+	for i < len(content) {
+		switch {
+		case strings.HasPrefix(content[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(content[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(content)
+}
+
+// skipString returns the index just past the string whose opening quote is at
+// i. holes tells that the string embeds expressions in braces, which may hold
+// strings of their own; verbatim that "" rather than a backslash escapes a
+// quote.
+func (s *cLikeSyntax) skipString(content string, i int, holes, verbatim bool) int {
+	triple := s.textBlocks && strings.HasPrefix(content[i:], `"""`)
+	escapes := !verbatim && !(triple && s.rawTextBlocks)
+	j := i + 1
+	if triple {
+		j = i + 3
+	}
+	for j < len(content) {
+		c := content[j]
+		switch {
+		case c == '\\' && escapes:
+			j += 2
+			continue
+		case triple && strings.HasPrefix(content[j:], `"""`):
+			j += 3
+			for j < len(content) && content[j] == '"' {
+				j++
+			}
+			return j
+		case !triple && c == '"':
+			if verbatim && j+1 < len(content) && content[j+1] == '"' {
+				j += 2
+				continue
+			}
+			return j + 1
+		case !triple && !verbatim && c == '\n':
+			// Unterminated
+			return j
+		case holes && s.dollarHoles && c == '$' && j+1 < len(content) && content[j+1] == '{':
+			j = s.skipHole(content, j+1)
+			continue
+		case holes && !s.dollarHoles && c == '{':
+			if j+1 < len(content) && content[j+1] == '{' {
+				j += 2
+				continue
+			}
+			j = s.skipHole(content, j)
+			continue
+		}
+		j++
+	}
+	return len(content)
+}
+
+// skipHole returns the index just past the embedded expression whose "{" is
+// at i.
+func (s *cLikeSyntax) skipHole(content string, i int) int {
+	depth := 0
+	for j := i; j < len(content); j++ {
+		switch content[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		case '"':
+			j = s.skipString(content, j, s.dollarHoles, false) - 1
+		case '\'':
+			j = skipCLikeChar(content, j) - 1
+		}
+	}
+	return len(content)
+}
+
+// skipPreprocessorLine returns the index of the line break that ends the
+// preprocessor directive starting at i. A backslash before the line break
+// continues the directive on the next line.
+func skipPreprocessorLine(content string, i int) int {
+	for {
+		end := strings.IndexByte(content[i:], '\n')
+		if end == -1 {
+			return len(content)
+		}
+		i += end
+		if !strings.HasSuffix(strings.TrimRight(content[:i], "\r"), "\\") {
+			return i
+		}
+		i++
+	}
+}
+
+// skipRawCppString returns the index just past the C++ raw string whose
+// quote is at i, e.g. R"sql(...)sql".
+func skipRawCppString(content string, i int) int {
+	open := strings.IndexByte(content[i:], '(')
+	if open == -1 {
+		return len(content)
+	}
+	closing := ")" + content[i+1:i+open] + "\""
+	if end := strings.Index(content[i+open:], closing); end != -1 {
+		return i + open + end + len(closing)
+	}
+	return len(content)
+}
+
+// skipCLikeChar returns the index just past the char literal whose quote is at
+// i, or just past the quote when the literal does not close on its line.
+func skipCLikeChar(content string, i int) int {
+	for j := i + 1; j < len(content) && content[j] != '\n'; j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '\'':
+			return j + 1
+		}
+	}
+	return i + 1
+}
+
+func (t clToken) tokenText() string { return t.text }
+
+func (t clToken) tokenClass() tokenClass {
+	switch t.kind {
+	case clIdent:
+		return classIdent
+	case clPunct:
+		return classPunct
+	}
+	return classOther
+}
+
+// clParser holds the tokens of a C-like source and collects the declarations
+// found in them.
+type clParser struct {
+	tokenIndex
+	content string
+	tokens  []clToken
+	decls   []Function
+}
+
+func newCLikeParser(content string, syntax *cLikeSyntax) *clParser {
+	p := &clParser{content: content, tokens: syntax.tokenize(content)}
+	p.tokenIndex = newTokenIndex(p.tokens)
+	return p
+}
+
+// render joins tokens[from:to] into normalized text: words are separated by
+// a space and commas are followed by one, other tokens are joined directly.
+func (p *clParser) render(from, to int) string {
+	var sb strings.Builder
+	for j := from; j < to; j++ {
+		tok := p.tokens[j]
+		if j > from && (tok.kind != clPunct && p.tokens[j-1].kind != clPunct || p.is(j-1, ",")) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.text)
+	}
+	return sb.String()
+}
+
+// addDecl records the declaration spanning tokens[first:last+1] with the
+// comments directly above it and returns it for further details.
+func (p *clParser) addDecl(name, container string, kind DeclKind, first, last int) *Function {
+	p.decls = append(p.decls, p.declAt(name, container, kind, first, last))
+	return &p.decls[len(p.decls)-1]
+}
+
+// declAt returns the declaration spanning tokens[first:last+1] with the
+// comments directly above it.
+func (p *clParser) declAt(name, container string, kind DeclKind, first, last int) Function {
+	lo := 0
+	if first > 0 {
+		lo = p.tokens[first-1].end
+	}
+	pos := p.tokens[first].start
+	start := tsLeadingCommentStart(p.content, lo, pos)
+	stop := p.tokens[last].end
+	lineStart := strings.LastIndexByte(p.content[:start], '\n') + 1
+	return Function{
+		Name:      name,
+		Kind:      kind,
+		Container: container,
+		Doc:       p.content[start:pos],
+		FullText:  p.content[start:stop],
+		StartPos:  start,
+		EndPos:    stop,
+		Indent:    p.content[lineStart:start],
+	}
+}
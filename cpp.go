@@ -0,0 +1,706 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cSpecifiers may precede the return type of a function without being part
+// of it.
+var cSpecifiers = map[string]bool{
+	"static": true, "inline": true, "extern": true, "virtual": true, "explicit": true,
+	"friend": true, "constexpr": true, "consteval": true, "constinit": true,
+	"__inline": true, "__forceinline": true, "_Noreturn": true,
+}
+
+// cAttributeCalls are followed by parentheses that do not hold parameters.
+var cAttributeCalls = map[string]bool{
+	"__attribute__": true, "__declspec": true, "alignas": true, "decltype": true,
+}
+
+// cTypeWords are the keywords that may end a parameter type, so an unnamed
+// parameter such as "unsigned int" keeps its last word.
+var cTypeWords = map[string]bool{
+	"int": true, "char": true, "short": true, "long": true, "float": true, "double": true,
+	"void": true, "bool": true, "unsigned": true, "signed": true, "const": true,
+	"volatile": true, "auto": true, "_Bool": true,
+}
+
+// cParam is a parameter of a function signature.
+type cParam struct {
+	end   int    // offset just past the parameter without its default argument
+	value string // the default argument, or ""
+}
+
+// cFunction is a function definition or declaration with the byte ranges of
+// the signature parts that syncing a declaration rewrites.
+type cFunction struct {
+	fn         Function
+	decl       int // index of the definition in the parser's decls, or -1
+	definition bool
+	ret        [2]int // return type without specifiers; empty for constructors
+	params     [2]int // between the parentheses
+	trail      [2]int // qualifiers after the parentheses, e.g. " const noexcept"
+	args       []cParam
+	types      string // parameter types, e.g. "const char*, int"
+}
+
+// cParser recognizes the functions, classes, types and namespaces of a C or
+// C++ source.
+type cParser struct {
+	*clParser
+	cpp   bool
+	funcs []cFunction
+}
+
+// parseCSource parses content. className names the class whose members
+// content holds, so that a lone constructor definition is recognized.
+func parseCSource(content string, cpp bool, syntax *cLikeSyntax, className string) *cParser {
+	p := &cParser{clParser: newCLikeParser(content, syntax), cpp: cpp}
+	p.parseItems(0, len(p.tokens), "", className)
+	return p
+}
+
+// extractCDeclarations returns the function definitions of content, with
+// qualified names such as "Foo::bar" for out-of-line members, and its
+// namespaces, classes, structs and enums. Functions carry their parameter
+// types in Signature, which tells overloads apart.
+func extractCDeclarations(content string, cpp bool, syntax *cLikeSyntax) []Function {
+	p := parseCSource(content, cpp, syntax, "")
+	for _, f := range p.funcs {
+		if f.definition {
+			p.decls[f.decl].Signature = f.types
+		}
+	}
+	return p.decls
+}
+
+// cQualifiedName joins the container path and name of fn with "::".
+func cQualifiedName(fn Function) string {
+	return strings.ReplaceAll(qualify(fn.Container, fn.Name), ".", "::")
+}
+
+// skipPrefix returns the index of the first token at or after i that is not
+// part of a template header or an attribute.
+func (p *cParser) skipPrefix(i, end int) int {
+	for i < end {
+		switch {
+		case p.is(i, "template") && p.is(i+1, "<"):
+			i = p.skipAngles(i+1, end)
+		case p.is(i, "[") && p.is(i+1, "["):
+			i = p.closing(i, end) + 1
+		case p.isIdent(i) && cAttributeCalls[p.tokens[i].text] && p.is(i+1, "("):
+			i = p.closing(i+1, end) + 1
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipStatement returns the index just past the declaration or statement at
+// i: its ";" or, unless it is an initializer, the "}" of its block.
+func (p *cParser) skipStatement(i, end int) int {
+	assigned := false
+	for j := i; j < end; j++ {
+		switch {
+		case p.is(j, ";"):
+			return j + 1
+		case p.is(j, "="):
+			assigned = true
+		case p.is(j, "{") && !assigned:
+			return p.closing(j, end) + 1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	return end
+}
+
+// parseItems walks the declarations in tokens[from:to]. className is the
+// simple name of the enclosing class, which constructors repeat.
+func (p *cParser) parseItems(from, to int, container, className string) {
+	for i := from; i < to; {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+		k := p.skipPrefix(i, to)
+		if k >= to {
+			break
+		}
+
+		next := -1
+		switch {
+		case (p.is(k, "public") || p.is(k, "private") || p.is(k, "protected")) && className != "":
+			// An access specifier, such as "public:" or Qt's "public slots:"
+			j := k + 1
+			for p.isIdent(j) {
+				j++
+			}
+			if p.is(j, ":") && !p.is(j+1, ":") {
+				next = j + 1
+			}
+		case p.is(k, "extern") && k+1 < to && p.tokens[k+1].kind == clLiteral && p.is(k+2, "{"):
+			last := p.closing(k+2, to)
+			p.parseItems(k+3, last, container, className)
+			next = last + 1
+		case p.is(k, "namespace") || p.is(k, "inline") && p.is(k+1, "namespace"):
+			next = p.parseNamespace(i, k, to, container)
+		case p.is(k, "typedef"):
+			next = p.parseTypedef(i, k, to, container)
+		case p.is(k, "class") || p.is(k, "struct") || p.is(k, "union") || p.is(k, "enum"):
+			next = p.parseType(i, k, to, container)
+			if next == -1 {
+				// A use of the type, e.g. the return type of a function
+				next = p.parseFunction(i, k, to, container, className)
+			}
+		case p.is(k, "using") || p.is(k, "static_assert") || p.is(k, "friend") && (p.is(k+1, "class") || p.is(k+1, "struct")):
+		default:
+			next = p.parseFunction(i, k, to, container, className)
+		}
+		if next > i {
+			i = next
+			continue
+		}
+		i = p.skipStatement(k, to)
+	}
+}
+
+// parseNamespace handles "namespace a::b { ... }" whose keyword, or the
+// "inline" before it, is at k. An anonymous namespace adds no container.
+func (p *cParser) parseNamespace(first, k, end int, container string) int {
+	if p.is(k, "inline") {
+		k++
+	}
+	j := k + 1
+	for j < end && !p.is(j, "{") && !p.is(j, "=") && !p.is(j, ";") {
+		j++
+	}
+	if !p.is(j, "{") {
+		return -1
+	}
+	last := p.closing(j, end)
+	name := strings.ReplaceAll(p.render(k+1, j), "::", ".")
+	if name == "" {
+		p.parseItems(j+1, last, container, "")
+		return last + 1
+	}
+	p.addDecl(name, container, DeclNamespace, first, last)
+	p.parseItems(j+1, last, qualify(container, name), "")
+	return last + 1
+}
+
+// parseType handles a class, struct, union or enum definition whose keyword
+// is at k, up to the ";" after its body. C++ classes, structs and unions are
+// blocks whose members are parsed; enums and C types are replaced as a
+// whole. It returns -1 when the keyword does not start a definition.
+func (p *cParser) parseType(first, k, end int, container string) int {
+	keyword := p.tokens[k].text
+	j := k + 1
+	if keyword == "enum" && (p.is(j, "class") || p.is(j, "struct")) {
+		j++
+	}
+	j = p.skipPrefix(j, end)
+	name := ""
+	if p.isIdent(j) && !p.is(j, "final") {
+		name = p.tokens[j].text
+		j++
+	}
+	if p.is(j, "final") {
+		j++
+	}
+	if p.is(j, ":") && !p.is(j+1, ":") {
+		// Base classes or the underlying type of an enum
+		for j < end && !p.is(j, "{") && !p.is(j, ";") {
+			switch {
+			case p.is(j, "<"):
+				j = p.skipAngles(j, end) - 1
+			case p.isOpen(j):
+				j = p.closing(j, end)
+			}
+			j++
+		}
+	}
+	if !p.is(j, "{") {
+		return -1
+	}
+	closeBrace := p.closing(j, end)
+	last := p.skipStatement(closeBrace+1, end) - 1
+	if name == "" {
+		return last + 1
+	}
+	if !p.cpp || keyword == "enum" {
+		p.addDecl(name, container, DeclType, first, last)
+		return last + 1
+	}
+	p.addDecl(name, container, DeclClass, first, last)
+	p.parseItems(j+1, closeBrace, qualify(container, name), name)
+	return last + 1
+}
+
+// parseTypedef handles "typedef struct { ... } name;" whose keyword is at k.
+// Other typedefs are left to the caller.
+func (p *cParser) parseTypedef(first, k, end int, container string) int {
+	j := k + 1
+	if !p.is(j, "struct") && !p.is(j, "union") && !p.is(j, "enum") {
+		return -1
+	}
+	for j < end && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	if !p.is(j, "{") {
+		return -1
+	}
+	last := p.skipStatement(p.closing(j, end)+1, end) - 1
+	name := last - 1
+	for name > j && !p.isIdent(name) {
+		name--
+	}
+	if !p.is(last, ";") || !p.isIdent(name) {
+		return -1
+	}
+	p.addDecl(p.tokens[name].text, container, DeclType, first, last)
+	return last + 1
+}
+
+// parseFunction handles a function definition or declaration whose return
+// type, or name for a constructor, is at k and whose template header and
+// attributes start at first. Definitions are added to decls; both are added
+// to funcs.
+func (p *cParser) parseFunction(first, k, end int, container, className string) int {
+	open, operator := -1, -1
+	for j := k; j < end && open == -1; j++ {
+		switch {
+		case p.is(j, ";") || p.is(j, "{") || p.is(j, "=") || p.is(j, "}"):
+			return -1
+		case p.is(j, "operator"):
+			operator = j
+			if p.is(j+1, "(") && p.is(j+2, ")") {
+				j += 2
+			}
+			for j+1 < end && !p.is(j+1, "(") {
+				j++
+			}
+		case p.is(j, "("):
+			if p.isIdent(j-1) && cAttributeCalls[p.tokens[j-1].text] {
+				j = p.closing(j, end)
+				continue
+			}
+			if p.is(j+1, "*") || p.is(j+1, "&") || p.is(j+1, "^") {
+				// A function pointer
+				return -1
+			}
+			open = j
+		case p.is(j, "<") && j > k && (p.isIdent(j-1) || p.is(j-1, ">")):
+			j = p.skipAngles(j, end) - 1
+		case p.is(j, "["):
+			j = p.closing(j, end)
+		}
+	}
+	if open == -1 {
+		return -1
+	}
+
+	nameStart := open - 1
+	if operator != -1 {
+		nameStart = operator
+	} else if !p.isIdent(nameStart) {
+		return -1
+	}
+	if p.is(nameStart-1, "~") {
+		nameStart--
+	}
+	for p.is(nameStart-1, ":") && p.is(nameStart-2, ":") {
+		// The qualification, e.g. ns::Foo<T>::
+		q := nameStart - 3
+		if p.is(q, ">") {
+			q = p.angleOpen(q, k) - 1
+		}
+		if !p.isIdent(q) {
+			break
+		}
+		nameStart = q
+	}
+	name := p.functionName(nameStart, open, operator)
+
+	retStart := k
+	for retStart < nameStart-1 && p.isSpecifier(retStart) {
+		if p.is(retStart, "extern") && p.tokens[retStart+1].kind == clLiteral {
+			retStart++
+		}
+		retStart++
+	}
+	qualified := strings.Split(name, "::")
+	simple := qualified[len(qualified)-1]
+	if len(qualified) > 1 && qualified[len(qualified)-2] == simple {
+		className = simple
+	}
+	if retStart == nameStart && simple != className && !strings.HasPrefix(simple, "~") && operator == -1 {
+		// Neither a return type nor a constructor: a macro call
+		return -1
+	}
+
+	closeParen := p.closing(open, end)
+	trailEnd := p.trailEnd(closeParen+1, end)
+	last, definition, initList := -1, false, false
+	for j := trailEnd; j < end && last == -1; j++ {
+		switch {
+		case p.is(j, ";"):
+			last = j
+		case p.is(j, "{"):
+			if initList && (p.isIdent(j-1) || p.is(j-1, ">")) {
+				// The brace initializer of a member
+				j = p.closing(j, end)
+				continue
+			}
+			last = p.closing(j, end)
+			definition = true
+		case p.is(j, ":") && !p.is(j+1, ":") && !p.is(j-1, ":"):
+			initList = true
+		case p.is(j, "}"):
+			return -1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	if last == -1 {
+		return -1
+	}
+
+	f := cFunction{
+		decl:       -1,
+		definition: definition,
+		params:     [2]int{p.tokens[open].end, p.tokens[closeParen].start},
+		trail:      [2]int{p.tokens[closeParen].end, p.tokens[closeParen].end},
+	}
+	if retStart < nameStart {
+		f.ret = [2]int{p.tokens[retStart].start, p.tokens[nameStart-1].end}
+	}
+	if trailEnd > closeParen+1 {
+		f.trail[1] = p.tokens[trailEnd-1].end
+	}
+	f.args, f.types = p.parameters(open, closeParen)
+	if definition {
+		f.decl = len(p.decls)
+		f.fn = *p.addDecl(name, container, DeclFunc, first, last)
+	} else {
+		f.fn = p.declAt(name, container, DeclFunc, first, last)
+	}
+	p.funcs = append(p.funcs, f)
+	return last + 1
+}
+
+// isSpecifier tells whether the token at i is a specifier or an upper-case
+// macro, such as an export macro, in front of a return type.
+func (p *cParser) isSpecifier(i int) bool {
+	if !p.isIdent(i) {
+		return false
+	}
+	text := p.tokens[i].text
+	if cSpecifiers[text] {
+		return true
+	}
+	return len(text) > 1 && strings.ToUpper(text) == text && strings.ContainsAny(text, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// angleOpen returns the index of the "<" that the ">" at i closes, looking
+// no further back than lo.
+func (p *cParser) angleOpen(i, lo int) int {
+	depth := 0
+	for j := i; j >= lo; j-- {
+		switch {
+		case p.is(j, ">"):
+			depth++
+		case p.is(j, "<"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return lo
+}
+
+// functionName renders the name in tokens[from:to], such as "Foo::bar",
+// "Foo::~Foo" or "operator==", without the template arguments of its
+// qualification.
+func (p *cParser) functionName(from, to, operator int) string {
+	var sb strings.Builder
+	for j := from; j < to; j++ {
+		if p.is(j, "<") && (operator == -1 || j < operator) {
+			j = p.skipAngles(j, to) - 1
+			continue
+		}
+		if j > from && p.isIdent(j) && p.isIdent(j-1) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(p.tokens[j].text)
+	}
+	return sb.String()
+}
+
+// trailEnd returns the index just past the qualifiers that follow the
+// parameter list starting at i: cv and ref qualifiers, noexcept and throw
+// specifications and a trailing return type.
+func (p *cParser) trailEnd(i, end int) int {
+	for i < end {
+		switch {
+		case p.is(i, "const") || p.is(i, "volatile") || p.is(i, "&") || p.is(i, "mutable"):
+			i++
+		case p.is(i, "noexcept") || p.is(i, "throw"):
+			i++
+			if p.is(i, "(") {
+				i = p.closing(i, end) + 1
+			}
+		case p.is(i, "-") && p.is(i+1, ">"):
+			i += 2
+			for i < end && !p.is(i, "{") && !p.is(i, ";") && !p.is(i, "=") && !p.is(i, "override") && !p.is(i, "final") &&
+				!(p.is(i, ":") && !p.is(i+1, ":") && !p.is(i-1, ":")) && !(p.is(i, "[") && p.is(i+1, "[")) {
+				switch {
+				case p.is(i, "<"):
+					i = p.skipAngles(i, end)
+					continue
+				case p.isOpen(i):
+					i = p.closing(i, end)
+				}
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// parameters returns the parameters between the parentheses at open and
+// closeParen and their types without names and default arguments.
+func (p *cParser) parameters(open, closeParen int) ([]cParam, string) {
+	var params []cParam
+	var types []string
+	start := open + 1
+	assign := -1
+	for j := open + 1; j <= closeParen; j++ {
+		switch {
+		case j == closeParen || p.is(j, ","):
+			if start == j {
+				break
+			}
+			param := cParam{end: p.tokens[j-1].end}
+			typeEnd := j
+			if assign != -1 {
+				param.end = p.tokens[assign-1].end
+				param.value = p.content[p.tokens[assign+1].start:p.tokens[j-1].end]
+				typeEnd = assign
+			}
+			params = append(params, param)
+			if t := p.paramType(start, typeEnd); t != "void" {
+				types = append(types, t)
+			}
+			start, assign = j+1, -1
+		case p.is(j, "=") && assign == -1:
+			assign = j
+		case p.is(j, "<") && assign == -1 && (p.isIdent(j-1) || p.is(j-1, ">")):
+			j = min(p.skipAngles(j, closeParen), closeParen) - 1
+		case p.isOpen(j):
+			j = p.closing(j, closeParen)
+		}
+	}
+	return params, strings.Join(types, ", ")
+}
+
+// paramType renders the type of the parameter in tokens[from:to] without its
+// name, e.g. "const char*" for "const char *name" and "int[]" for "int v[]".
+func (p *cParser) paramType(from, to int) string {
+	suffix := ""
+	j := to - 1
+	for p.is(j, "]") && p.match[j] > from {
+		suffix = "[]" + suffix
+		j = p.match[j] - 1
+	}
+	if j > from && p.isIdent(j) && !cTypeWords[p.tokens[j].text] && !p.is(j-1, ":") {
+		return p.render(from, j) + suffix
+	}
+	return p.render(from, to)
+}
+
+// cLanguage syncs C or C++ sources and keeps the declarations of changed
+// definitions in sync, in the target itself and in its paired header.
+type cLanguage struct {
+	name       string
+	aliases    []string
+	extensions []string
+	indicators []string
+	cpp        bool
+	syntax     cLikeSyntax
+}
+
+var (
+	langC = &cLanguage{
+		name:       "c",
+		extensions: []string{".c", ".h"},
+		indicators: []string{"#include", "#define", "printf(", "malloc(", "sizeof(", "typedef ", "void *", "int main(", "unsigned ", "const char *"},
+		syntax:     cLikeSyntax{preprocessor: true},
+	}
+	langCPP = &cLanguage{
+		name:       "cpp",
+		aliases:    []string{"c++", "cxx"},
+		extensions: []string{".cc", ".cpp", ".cxx", ".c++", ".hpp", ".hh", ".hxx"},
+		indicators: []string{"#include", "std::vector", "std::string", "nullptr", "template<", "template <", "public:", "private:", "virtual ", "#pragma once", "const std::"},
+		cpp:        true,
+		syntax:     cLikeSyntax{preprocessor: true, rawStrings: true},
+	}
+)
+
+// cSourceExtensions are the extensions of files whose declarations live in
+// a paired header.
+var cSourceExtensions = map[string]bool{".c": true, ".cc": true, ".cpp": true, ".cxx": true, ".c++": true}
+
+func (l *cLanguage) Name() string         { return l.name }
+func (l *cLanguage) Family() string       { return "C/C++" }
+func (l *cLanguage) Aliases() []string    { return l.aliases }
+func (l *cLanguage) Extensions() []string { return l.extensions }
+
+func (l *cLanguage) ContentScore(content string) int {
+	return countIndicators(content, l.indicators)
+}
+
+func (l *cLanguage) Extract(content string) ([]Function, error) {
+	return extractCDeclarations(content, l.cpp, &l.syntax), nil
+}
+
+// Key joins namespaces, classes and the name with "::", e.g. "ns::Foo::bar".
+func (l *cLanguage) Key(fn Function) string {
+	return cQualifiedName(fn)
+}
+
+// Insert places new inline members before the closing brace of their class
+// and new entries of a namespace into that namespace.
+func (l *cLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	insertions, rest := blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions)
+	for i := range insertions {
+		// A class ends with "};"
+		block := &insertions[i].block
+		block.EndPos = block.StartPos + strings.LastIndexByte(block.FullText, '}') + 1
+	}
+	return braceMemberEdits(targetContent, insertions), rest
+}
+
+// Finish updates the declarations in the target whose definitions changed,
+// such as forward declarations or members declared in a class.
+func (l *cLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	return l.SyncDeclarations(content, synced), nil
+}
+
+// PairedHeader returns the header next to a C or C++ source file, or in an
+// "include" directory beside or above it, with the same base name.
+func (l *cLanguage) PairedHeader(path string) string {
+	ext := filepath.Ext(path)
+	if !cSourceExtensions[strings.ToLower(ext)] {
+		return ""
+	}
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	dir := filepath.Dir(path)
+	for _, candidateDir := range []string{dir, filepath.Join(dir, "include"), filepath.Join(dir, "..", "include")} {
+		for _, headerExt := range []string{".h", ".hpp", ".hh", ".hxx"} {
+			candidate := filepath.Join(candidateDir, base+headerExt)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// SyncDeclarations rewrites the declarations in content whose definition
+// among definitions has a different return type, parameter list or
+// qualifiers. Default arguments only the declaration has are kept. An
+// overloaded name is only synced when the parameter types pick one
+// declaration.
+func (l *cLanguage) SyncDeclarations(content string, definitions []Function) string {
+	declarations := make(map[string][]cFunction)
+	for _, f := range parseCSource(content, l.cpp, &l.syntax, "").funcs {
+		if !f.definition {
+			declarations[cQualifiedName(f.fn)] = append(declarations[cQualifiedName(f.fn)], f)
+		}
+	}
+
+	var edits []textEdit
+	for _, def := range definitions {
+		candidates := declarations[cQualifiedName(def)]
+		if def.Kind != DeclFunc || len(candidates) == 0 {
+			continue
+		}
+		className := def.Container[strings.LastIndexByte(def.Container, '.')+1:]
+		var parsed *cFunction
+		funcs := parseCSource(def.FullText, l.cpp, &l.syntax, className).funcs
+		for i := range funcs {
+			if funcs[i].definition {
+				parsed = &funcs[i]
+				break
+			}
+		}
+		if parsed == nil {
+			continue
+		}
+		if len(candidates) > 1 {
+			var matching []cFunction
+			for _, f := range candidates {
+				if f.types == parsed.types {
+					matching = append(matching, f)
+				}
+			}
+			if len(matching) != 1 {
+				log.Printf("Предупреждение: у перегруженной функции %s несколько объявлений, объявление не обновлено", cQualifiedName(def))
+				continue
+			}
+			candidates = matching
+		}
+		edits = append(edits, declarationEdits(content, candidates[0], def.FullText, *parsed)...)
+	}
+	return applyEdits(content, edits)
+}
+
+// declarationEdits returns the edits that give the declaration decl in
+// content the return type, parameters and qualifiers of the definition def
+// parsed from defText.
+func declarationEdits(content string, decl cFunction, defText string, def cFunction) []textEdit {
+	compact := func(text string) string {
+		return strings.Join(strings.Fields(text), "")
+	}
+	var edits []textEdit
+
+	ret := strings.TrimSpace(defText[def.ret[0]:def.ret[1]])
+	if decl.ret[0] < decl.ret[1] && ret != "" && compact(ret) != compact(content[decl.ret[0]:decl.ret[1]]) {
+		edits = append(edits, textEdit{start: decl.ret[0], end: decl.ret[1], text: ret})
+	}
+
+	params := defText[def.params[0]:def.params[1]]
+	if len(decl.args) == len(def.args) {
+		var sb strings.Builder
+		last := def.params[0]
+		for i, arg := range def.args {
+			if arg.value == "" && decl.args[i].value != "" {
+				sb.WriteString(defText[last:arg.end])
+				sb.WriteString(" = " + decl.args[i].value)
+				last = arg.end
+			}
+		}
+		sb.WriteString(defText[last:def.params[1]])
+		params = sb.String()
+	}
+	if compact(params) != compact(content[decl.params[0]:decl.params[1]]) {
+		edits = append(edits, textEdit{start: decl.params[0], end: decl.params[1], text: params})
+	}
+
+	trail := strings.TrimSpace(defText[def.trail[0]:def.trail[1]])
+	if compact(trail) != compact(content[decl.trail[0]:decl.trail[1]]) {
+		if trail != "" {
+			trail = " " + trail
+		}
+		edits = append(edits, textEdit{start: decl.trail[0], end: decl.trail[1], text: trail})
+	}
+	return edits
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractCDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		content  string
+		expected []string
+	}{
+		{
+			name: "Функции и типы C",
+			lang: langC,
+			content: "#include <stdio.h>\n#define MAX(a, b) \\\n    ((a) > (b) ? (a) : (b))\n\nstruct point { int x, y; };\n" +
+				"typedef struct { int w; } size;\nenum color { RED, GREEN };\n\nint area(const size *s);\n" +
+				"static inline int area(const size *s) { return s->w * s->w; }\nchar *names[] = {\"}\"};\n" +
+				"API_EXPORT void *alloc(unsigned int n, void (*cb)(int)) { return NULL; }\nint main(void) { return 0; }\n",
+			expected: []string{"point", "size", "color", "area", "alloc", "main"},
+		},
+		{
+			name: "Внешние определения членов и перегрузки C++",
+			lang: langCPP,
+			content: "namespace ui {\nWidget::Widget(int w) : w_(w), name_{\"}\"} {}\nWidget::~Widget() = default;\n" +
+				"template <typename T>\nT Widget::clamp(T v) const noexcept { return v; }\n" +
+				"bool Widget::operator==(const Widget& o) const { return w_ == o.w_; }\n" +
+				"int Widget::resize(int w) { return 1'000; }\nint Widget::resize(int w, int h) { return h; }\n}\n" +
+				"auto sql = R\"sql(SELECT \"}\" FROM t)sql\";\nauto next(int x) -> int { return x + 1; }\n",
+			expected: []string{"ui", "ui::Widget::Widget", "ui::Widget::clamp", "ui::Widget::operator==", "ui::Widget::resize(int)", "ui::Widget::resize(int, int)", "next"},
+		},
+		{
+			name: "Классы C++ и их встроенные члены",
+			lang: langCPP,
+			content: "namespace {\nint hidden() { return 0; }\n}\nclass Layout final : public Base<int> {\npublic:\n" +
+				"    explicit Layout(int gap) : gap_(gap) {}\n    virtual ~Layout() {}\n    int gap() const { return gap_; }\n" +
+				"    struct Item { int size() { return 0; } };\nprivate:\n    int gap_ = 0;\n};\nenum class Align : int { Left, Right };\n",
+			expected: []string{"hidden", "Layout", "Layout::Layout", "Layout::~Layout", "Layout::gap", "Layout::Item", "Layout::Item::size", "Align"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			declarations, err := tt.lang.Extract(tt.content)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений: %v", err)
+			}
+			keyOf := matchKeys(tt.lang, declarations, nil)
+			var keys []string
+			for _, decl := range declarations {
+				keys = append(keys, keyOf(decl))
+				if got := tt.content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+					t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+				}
+			}
+			if strings.Join(keys, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Ожидались ключи %v, получено %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_C(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		source   string
+		target   string
+		expected string
+	}{
+		{
+			name:     "C",
+			lang:     langC,
+			source:   "testdata/c_source.c",
+			target:   "testdata/c_target.c",
+			expected: "testdata/c_expected.c",
+		},
+		{
+			name:     "C++",
+			lang:     langCPP,
+			source:   "testdata/cpp_source.cpp",
+			target:   "testdata/cpp_target.cpp",
+			expected: "testdata/cpp_expected.cpp",
+		},
+		{
+			name:     "Одна перегрузка из двух",
+			lang:     langCPP,
+			source:   "testdata/cpp_overload_source.cpp",
+			target:   "testdata/cpp_overload_target.cpp",
+			expected: "testdata/cpp_overload_expected.cpp",
+		},
+		{
+			name:     "Члены пространства имён без отступа",
+			lang:     langCPP,
+			source:   "testdata/cpp_namespace_source.cpp",
+			target:   "testdata/cpp_namespace_target.cpp",
+			expected: "testdata/cpp_namespace_expected.cpp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacer := NewFunctionReplacer()
+
+			sourceContent, err := readFile(tt.source)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.source, err)
+			}
+			targetContent, err := readFile(tt.target)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.target, err)
+			}
+			expected, err := readFile(tt.expected)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.expected, err)
+			}
+
+			sourceDeclarations, err := tt.lang.Extract(sourceContent)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+			}
+			result := replacer.replaceFunctions(targetContent, sourceDeclarations, tt.lang)
+			result, err = tt.lang.Finish(replacer, result, sourceContent, sourceDeclarations)
+			if err != nil {
+				t.Fatalf("Ошибка завершения синхронизации: %v", err)
+			}
+			if result != expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+			}
+
+			again := replacer.replaceFunctions(result, sourceDeclarations, tt.lang)
+			if again, _ = tt.lang.Finish(replacer, again, sourceContent, sourceDeclarations); again != result {
+				t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestSyncDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     *cLanguage
+		header   string
+		source   string
+		expected string
+	}{
+		{
+			name:     "Возвращаемый тип и параметры прототипа C",
+			lang:     langC,
+			header:   "/* Adds step. */\nextern int counter_add(struct counter *c, int step);\nvoid counter_reset(struct counter *c);\n",
+			source:   "long counter_add(struct counter *c, long step) { return 0; }\nvoid counter_reset(struct counter *c) { c->value = 0; }\n",
+			expected: "/* Adds step. */\nextern long counter_add(struct counter *c, long step);\nvoid counter_reset(struct counter *c);\n",
+		},
+		{
+			name:     "Аргументы по умолчанию и квалификаторы сохраняются",
+			lang:     langCPP,
+			header:   "class Widget {\npublic:\n    virtual int resize(int w, int h = 0) override;\n};\n",
+			source:   "long Widget::resize(int width, int height) const noexcept { return 0; }\n",
+			expected: "class Widget {\npublic:\n    virtual long resize(int width, int height = 0) const noexcept override;\n};\n",
+		},
+		{
+			name:     "Перегрузка выбирается по типам параметров",
+			lang:     langCPP,
+			header:   "namespace ui {\nclass Widget {\n    int resize(int w);\n    int resize(int w, int h);\n};\n}\n",
+			source:   "namespace ui {\nint Widget::resize(int w) { return w; }\nlong Widget::resize(int w, int h) { return h; }\n}\n",
+			expected: "namespace ui {\nclass Widget {\n    int resize(int w);\n    long resize(int w, int h);\n};\n}\n",
+		},
+		{
+			name:     "Неоднозначная перегрузка не обновляется",
+			lang:     langCPP,
+			header:   "int parse(const char *s);\nint parse(std::string_view s);\n",
+			source:   "int parse(const char *s, int base) { return 0; }\n",
+			expected: "int parse(const char *s);\nint parse(std::string_view s);\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := tt.lang.Extract(tt.source)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений: %v", err)
+			}
+			if got := tt.lang.SyncDeclarations(tt.header, definitions); got != tt.expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Заголовочный файл C++", func(t *testing.T) {
+		sourceContent, err := readFile("testdata/cpp_source.cpp")
+		if err != nil {
+			t.Fatalf("Не удалось прочитать исходник: %v", err)
+		}
+		header, err := readFile("testdata/cpp_header.hpp")
+		if err != nil {
+			t.Fatalf("Не удалось прочитать заголовок: %v", err)
+		}
+		expected, err := readFile("testdata/cpp_header_expected.hpp")
+		if err != nil {
+			t.Fatalf("Не удалось прочитать ожидаемый заголовок: %v", err)
+		}
+		definitions, _ := langCPP.Extract(sourceContent)
+		if got := langCPP.SyncDeclarations(header, definitions); got != expected {
+			t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, got)
+		}
+	})
+}
+
+func TestPairedHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{"counter.c", "counter.h", "src/widget.cpp", "include/widget.hpp", "lib/parser.cc", "lib/include/parser.hh", "alone.c"}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Не удалось создать файл %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{source: "counter.c", expected: "counter.h"},
+		{source: "src/widget.cpp", expected: "include/widget.hpp"},
+		{source: "lib/parser.cc", expected: "lib/include/parser.hh"},
+		{source: "alone.c", expected: ""},
+		{source: "counter.h", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got := langCPP.PairedHeader(filepath.Join(tmpDir, tt.source))
+			expected := ""
+			if tt.expected != "" {
+				expected = filepath.Join(tmpDir, tt.expected)
+			}
+			if got != expected {
+				t.Errorf("Ожидался заголовок %q, получен %q", expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 3
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// diffOp is one line of an edit script: ' ' keeps, '-' deletes, '+' inserts.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the unified diff that turns oldText into newText, or an
+// empty string when they are equal. name labels both sides like git does.
+func unifiedDiff(name, oldText, newText string, color bool) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitDiffLines(oldText), splitDiffLines(newText))
+
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+
+	label := strings.TrimPrefix(filepath.ToSlash(name), "/")
+	var sb strings.Builder
+	sb.WriteString(paint(colorBold, "--- a/"+label) + "\n")
+	sb.WriteString(paint(colorBold, "+++ b/"+label) + "\n")
+
+	for _, hunk := range diffHunks(ops) {
+		oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+		for _, op := range ops[:hunk[0]] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+		for _, op := range ops[hunk[0]:hunk[1]] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		header := fmt.Sprintf("@@ -%s +%s @@", diffRange(oldStart, oldCount), diffRange(newStart, newCount))
+		sb.WriteString(paint(colorCyan, header) + "\n")
+
+		for _, op := range ops[hunk[0]:hunk[1]] {
+			line := string(op.kind) + op.line
+			switch op.kind {
+			case '-':
+				line = paint(colorRed, line)
+			case '+':
+				line = paint(colorGreen, line)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// diffRange formats a hunk range; start is the number of lines before it.
+func diffRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitDiffLines splits text into lines without their newlines. A missing
+// final newline is marked the way diff(1) does.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file"
+	return lines
+}
+
+// diffHunks groups the changed ops into [start, end) index ranges padded with
+// up to diffContextLines unchanged lines; close changes share one hunk.
+func diffHunks(ops []diffOp) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+		start := max(i-diffContextLines, 0)
+		end := min(i+1+diffContextLines, len(ops))
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	return hunks
+}
+
+// diffMaxEdits bounds the edit distance that diffLines searches for in one
+// range. Past it the range is shown as deleted and inserted as a whole, which
+// is still a correct script but keeps the time bounded on unrelated texts.
+const diffMaxEdits = 1000
+
+// diffLines computes a shortest edit script between a and b with the
+// linear-space variant of Myers' algorithm: it finds where the forward and
+// backward searches meet and recurses on both halves, so memory stays
+// proportional to the input rather than to edits times lines. Within each
+// run of changes the deletions come before the insertions that replace them.
+func diffLines(a, b []string) []diffOp {
+	ops := appendDiff(nil, a, b)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		run := ops[start:end]
+		sort.SliceStable(run, func(i, j int) bool { return run[i].kind == '-' && run[j].kind == '+' })
+		start = end
+	}
+	return ops
+}
+
+// appendDiff appends the edit script between a and b to ops after trimming
+// their common prefix and suffix.
+func appendDiff(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if x, y, ok := myersSplit(midA, midB); ok {
+		ops = appendDiff(ops, midA[:x], midB[:y])
+		ops = appendDiff(ops, midA[x:], midB[y:])
+	} else {
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myersSplit returns a point on a shortest edit path between a and b, which
+// differ in their first and last lines, where the forward search from the
+// start meets the backward search from the end. It reports false when either
+// side is empty, the texts have nothing in common or the distance exceeds
+// diffMaxEdits; then everything in a is deleted and everything in b inserted.
+func myersSplit(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// With an odd delta the paths meet while searching forward
+	odd := delta%2 != 0
+	// Diagonals that ran off the edge are not searched again
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < min(maxD, diffMaxEdits); d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || k != d && forward[i-1] < forward[i+1] {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || k != d && backward[i-1] < backward[i+1] {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < len(forward) && forward[j] != -1 && forward[j] >= n-x {
+					fx := forward[j]
+					return fx, fx - (j - offset), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
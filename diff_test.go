@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name:     "no changes",
+			oldText:  "a\nb\n",
+			newText:  "a\nb\n",
+			expected: "",
+		},
+		{
+			name:    "replaced line with context",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			newText: "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: `--- a/target.go
++++ b/target.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:    "distant changes get separate hunks",
+			oldText: "a\n1\n2\n3\n4\n5\n6\n7\n8\nz\n",
+			newText: "A\n1\n2\n3\n4\n5\n6\n7\n8\nZ\n",
+			expected: `--- a/target.go
++++ b/target.go
+@@ -1,4 +1,4 @@
+-a
++A
+ 1
+ 2
+ 3
+@@ -7,4 +7,4 @@
+ 6
+ 7
+ 8
+-z
++Z
+`,
+		},
+		{
+			name:    "new file",
+			oldText: "",
+			newText: "package main\n",
+			expected: `--- a/target.go
++++ b/target.go
+@@ -0,0 +1 @@
++package main
+`,
+		},
+		{
+			name:    "appended function",
+			oldText: "package main\n\nfunc A() {}\n",
+			newText: "package main\n\nfunc A() {}\n\nfunc B() {}\n",
+			expected: `--- a/target.go
++++ b/target.go
+@@ -1,3 +1,5 @@
+ package main
+ 
+ func A() {}
++
++func B() {}
+`,
+		},
+		{
+			name:    "missing final newline",
+			oldText: "a\nb",
+			newText: "a\nb\n",
+			expected: `--- a/target.go
++++ b/target.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("target.go", tt.oldText, tt.newText, false); got != tt.expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_Color(t *testing.T) {
+	got := unifiedDiff("target.go", "old\n", "new\n", true)
+	for _, want := range []string{colorRed + "-old" + colorReset, colorGreen + "+new" + colorReset, colorCyan + "@@ -1 +1 @@" + colorReset} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Не найдена раскрашенная строка %q в:\n%q", want, got)
+		}
+	}
+}
+
+func TestDiffLines_ShortestScript(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+
+	changes := 0
+	var rebuilt []string
+	for _, op := range diffLines(a, b) {
+		if op.kind != ' ' {
+			changes++
+		}
+		if op.kind != '-' {
+			rebuilt = append(rebuilt, op.line)
+		}
+	}
+	if strings.Join(rebuilt, " ") != strings.Join(b, " ") {
+		t.Errorf("Скрипт правок не восстанавливает b: %v", rebuilt)
+	}
+	if changes != 5 {
+		t.Errorf("Ожидалось 5 правок (классический пример Майерса), получено %d", changes)
+	}
+}
+
+func TestDiffLines_DeletionsFirst(t *testing.T) {
+	a := strings.Split("b a b", " ")
+	b := strings.Split("a c", " ")
+
+	var script []string
+	for _, op := range diffLines(a, b) {
+		script = append(script, string(op.kind)+op.line)
+	}
+	if got := strings.Join(script, " "); got != "-b  a -b +c" {
+		t.Errorf("Удаления должны идти перед вставками внутри изменения, получено %q", got)
+	}
+}
+
+func TestDiffLines_LargeInput(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{name: "every line changed"},
+		{name: "unrelated texts past diffMaxEdits"},
+	}
+	for i := 0; i < 12000; i++ {
+		line := fmt.Sprintf("line %d", i)
+		tests[0].a = append(tests[0].a, line+"\r")
+		tests[0].b = append(tests[0].b, line)
+		tests[1].a = append(tests[1].a, line)
+		tests[1].b = append(tests[1].b, fmt.Sprintf("other %d", i%(diffMaxEdits*3)))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oldLines, newLines []string
+			for _, op := range diffLines(tt.a, tt.b) {
+				if op.kind != '+' {
+					oldLines = append(oldLines, op.line)
+				}
+				if op.kind != '-' {
+					newLines = append(newLines, op.line)
+				}
+			}
+			if strings.Join(oldLines, "\n") != strings.Join(tt.a, "\n") || strings.Join(newLines, "\n") != strings.Join(tt.b, "\n") {
+				t.Error("Скрипт правок не восстанавливает исходный и новый текст")
+			}
+		})
+	}
+}
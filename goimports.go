@@ -0,0 +1,494 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goImportSpec is one import of a Go file.
+type goImportSpec struct {
+	name  string // explicit name such as "_", "." or an alias; empty when none
+	path  string
+	start int // start of the line holding the spec
+	end   int // just past the newline that ends the spec's line
+}
+
+// goImportDecl is one import declaration of a Go file.
+type goImportDecl struct {
+	grouped bool
+	start   int // start of the line holding the import keyword
+	end     int // just past the newline after ")" or after the single spec
+	lparen  int
+	rparen  int
+	specs   []goImportSpec
+}
+
+// goFileImports describes the package clause and imports of a Go file.
+type goFileImports struct {
+	packageClause string // "package name", empty for fragments without one
+	packageEnd    int    // just past the package clause line
+	decls         []goImportDecl
+}
+
+// parseGoImports reads the package clause and import declarations of content.
+// Everything after the imports is ignored, so the rest of the file does not
+// have to parse.
+func parseGoImports(content string) (goFileImports, error) {
+	var imports goFileImports
+	fset, file, prefixLen, err := parseGoSnippet(content, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return imports, err
+	}
+	tokFile := fset.File(file.Pos())
+	offset := func(pos token.Pos) int {
+		return tokFile.Offset(pos) - prefixLen
+	}
+
+	if prefixLen == 0 {
+		imports.packageClause = "package " + file.Name.Name
+		imports.packageEnd = lineEndAfter(content, offset(file.Name.End()))
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		importDecl := goImportDecl{
+			grouped: genDecl.Lparen.IsValid(),
+			start:   lineStartBefore(content, offset(genDecl.Pos())),
+			end:     lineEndAfter(content, offset(genDecl.End())),
+		}
+		if importDecl.grouped {
+			importDecl.lparen = offset(genDecl.Lparen)
+			importDecl.rparen = offset(genDecl.Rparen)
+		}
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			path, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := ""
+			if importSpec.Name != nil {
+				name = importSpec.Name.Name
+			}
+			end := offset(importSpec.End())
+			if importSpec.Comment != nil {
+				end = max(end, offset(importSpec.Comment.End()))
+			}
+			start := offset(importSpec.Pos())
+			if importSpec.Doc != nil {
+				start = offset(importSpec.Doc.Pos())
+			}
+			start, end = lineStartBefore(content, start), lineEndAfter(content, end)
+			if importDecl.grouped {
+				// Keep specs of a one-line block inside its parentheses
+				start, end = max(start, importDecl.lparen+1), min(end, importDecl.rparen)
+			}
+			importDecl.specs = append(importDecl.specs, goImportSpec{
+				name:  name,
+				path:  path,
+				start: start,
+				end:   end,
+			})
+		}
+		imports.decls = append(imports.decls, importDecl)
+	}
+	return imports, nil
+}
+
+// lineStartBefore returns the start of the line holding pos when only
+// whitespace precedes pos on it, and pos otherwise.
+func lineStartBefore(content string, pos int) int {
+	lineStart := strings.LastIndexByte(content[:pos], '\n') + 1
+	if strings.TrimSpace(content[lineStart:pos]) != "" {
+		return pos
+	}
+	return lineStart
+}
+
+// lineEndAfter returns the position just past the newline that ends the line
+// holding pos, or len(content) on the last line.
+func lineEndAfter(content string, pos int) int {
+	if idx := strings.IndexByte(content[pos:], '\n'); idx != -1 {
+		return pos + idx + 1
+	}
+	return len(content)
+}
+
+var goMajorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// goImportLocalName returns the identifier an import is referred to by. For
+// imports without an explicit name it guesses the package name from the path
+// the way goimports does: "github.com/gofiber/fiber/v2" is fiber,
+// "gopkg.in/yaml.v3" is yaml and "github.com/mattn/go-sqlite3" is sqlite3.
+func goImportLocalName(spec goImportSpec) string {
+	if spec.name != "" {
+		return spec.name
+	}
+	elems := strings.Split(spec.path, "/")
+	last := elems[len(elems)-1]
+	if len(elems) > 1 && goMajorVersionRegex.MatchString(last) {
+		last = elems[len(elems)-2]
+	}
+	if idx := strings.LastIndex(last, ".v"); idx > 0 && goMajorVersionRegex.MatchString(last[idx+1:]) {
+		last = last[:idx]
+	}
+	last = strings.TrimPrefix(last, "go-")
+	last = strings.TrimSuffix(strings.TrimSuffix(last, "-go"), ".go")
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, last)
+}
+
+// isGoStdImport reports whether path belongs to the standard library, whose
+// first path element never contains a dot.
+func isGoStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// goSelectorNames collects every identifier used as the left side of a
+// selector expression, e.g. "errors" in errors.New, in the given texts.
+func goSelectorNames(texts ...string) map[string]bool {
+	names := make(map[string]bool)
+	for _, text := range texts {
+		fset := token.NewFileSet()
+		file := fset.AddFile("", fset.Base(), len(text))
+		var s scanner.Scanner
+		s.Init(file, []byte(text), nil, 0)
+
+		prevIdent := ""
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok == token.PERIOD && prevIdent != "" {
+				names[prevIdent] = true
+			}
+			prevIdent = ""
+			if tok == token.IDENT {
+				prevIdent = lit
+			}
+		}
+	}
+	return names
+}
+
+// mergeGoImports adds the imports of sourceContent that the synced
+// declarations use and that targetContent lacks, keeping the target's
+// grouping and sort order. With PruneImports set, imports that nothing in the
+// result refers to anymore are removed afterwards.
+func (fr *FunctionReplacer) mergeGoImports(targetContent, sourceContent string, synced []Function) string {
+	result := targetContent
+
+	sourceImports, err := parseGoImports(sourceContent)
+	if err != nil {
+		log.Printf("Предупреждение: не удалось прочитать импорты исходного кода: %v", err)
+	}
+	if sourceImports.packageClause != "" && strings.TrimSpace(result) != "" && !hasGoPackageClause(result) {
+		// A new target file gets the source's package clause
+		result = sourceImports.packageClause + "\n\n" + strings.TrimLeft(result, "\n")
+	}
+	targetImports, err := parseGoImports(result)
+	if err != nil {
+		log.Printf("Предупреждение: не удалось прочитать импорты целевого файла: %v", err)
+		return result
+	}
+
+	texts := make([]string, len(synced))
+	for i, fn := range synced {
+		texts[i] = fn.FullText
+	}
+	used := goSelectorNames(texts...)
+
+	targetByName := make(map[string]string)
+	targetPaths := make(map[string]bool)
+	for _, decl := range targetImports.decls {
+		for _, spec := range decl.specs {
+			targetPaths[spec.path] = true
+			targetByName[goImportLocalName(spec)] = spec.path
+		}
+	}
+
+	var missing []goImportSpec
+	for _, decl := range sourceImports.decls {
+		for _, spec := range decl.specs {
+			name := goImportLocalName(spec)
+			if spec.name == "_" || spec.name == "." || !used[name] || targetPaths[spec.path] {
+				continue
+			}
+			if otherPath, exists := targetByName[name]; exists {
+				log.Printf("Предупреждение: импорт %q не добавлен: имя %s уже занято импортом %q", spec.path, name, otherPath)
+				continue
+			}
+			targetPaths[spec.path] = true
+			targetByName[name] = spec.path
+			missing = append(missing, spec)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Slice(missing, func(i, j int) bool {
+			return missing[i].path < missing[j].path
+		})
+		for _, spec := range missing {
+			log.Printf("Добавлен импорт %q", spec.path)
+		}
+		result = addGoImports(result, targetImports, missing)
+	}
+
+	if fr.PruneImports {
+		result = pruneGoImports(result)
+	}
+	return result
+}
+
+func formatGoImportSpec(spec goImportSpec) string {
+	if spec.name != "" {
+		return spec.name + " " + strconv.Quote(spec.path)
+	}
+	return strconv.Quote(spec.path)
+}
+
+// renderGoImportBlock renders specs as a grouped import declaration with the
+// standard library first, separated from other imports by a blank line.
+func renderGoImportBlock(specs []goImportSpec) string {
+	if len(specs) == 1 {
+		return "import " + formatGoImportSpec(specs[0]) + "\n"
+	}
+	sorted := append([]goImportSpec(nil), specs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iStd, jStd := isGoStdImport(sorted[i].path), isGoStdImport(sorted[j].path)
+		if iStd != jStd {
+			return iStd
+		}
+		return sorted[i].path < sorted[j].path
+	})
+
+	var sb strings.Builder
+	sb.WriteString("import (\n")
+	for i, spec := range sorted {
+		if i > 0 && isGoStdImport(spec.path) != isGoStdImport(sorted[i-1].path) {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\t" + formatGoImportSpec(spec) + "\n")
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+// addGoImports inserts missing imports into content. They join the group of
+// the first grouped import declaration that holds imports of the same kind
+// (standard library or not), at their sorted position; a new group is opened
+// when there is none.
+func addGoImports(content string, imports goFileImports, missing []goImportSpec) string {
+	var grouped *goImportDecl
+	for i := range imports.decls {
+		if imports.decls[i].grouped && len(imports.decls[i].specs) > 0 {
+			grouped = &imports.decls[i]
+			break
+		}
+	}
+
+	switch {
+	case grouped != nil:
+		return applyEdits(content, goImportGroupEdits(content, *grouped, missing))
+
+	case len(imports.decls) == 1:
+		// A single "import "fmt"" becomes a grouped declaration
+		decl := imports.decls[0]
+		block := renderGoImportBlock(append(append([]goImportSpec(nil), decl.specs...), missing...))
+		return applyEdits(content, []textEdit{{start: decl.start, end: decl.end, text: block}})
+
+	case len(imports.decls) > 1:
+		last := imports.decls[len(imports.decls)-1]
+		var sb strings.Builder
+		for _, spec := range missing {
+			sb.WriteString("import " + formatGoImportSpec(spec) + "\n")
+		}
+		return applyEdits(content, []textEdit{{start: last.end, end: last.end, text: sb.String()}})
+
+	case imports.packageClause != "":
+		return applyEdits(content, []textEdit{{start: imports.packageEnd, end: imports.packageEnd, text: "\n" + renderGoImportBlock(missing)}})
+
+	default:
+		return renderGoImportBlock(missing) + "\n" + content
+	}
+}
+
+// goImportGroupEdits returns the insertions that put missing imports into the
+// groups of a grouped import declaration.
+func goImportGroupEdits(content string, decl goImportDecl, missing []goImportSpec) []textEdit {
+	// Groups are runs of specs separated by blank lines
+	var groups [][]goImportSpec
+	for i, spec := range decl.specs {
+		if i == 0 || hasBlankLine(content[decl.specs[i-1].end:spec.start]) {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], spec)
+	}
+
+	first := decl.specs[0]
+	rest := content[first.start:]
+	indent := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+	if indent == "" {
+		indent = "\t"
+	}
+
+	var edits []textEdit
+	var newStd, newOther []goImportSpec
+	for _, spec := range missing {
+		std := isGoStdImport(spec.path)
+		group := bestGoImportGroup(groups, spec.path, std)
+		if group == nil {
+			if std {
+				newStd = append(newStd, spec)
+			} else {
+				newOther = append(newOther, spec)
+			}
+			continue
+		}
+		pos := group[len(group)-1].end
+		for _, existing := range group {
+			if existing.path > spec.path {
+				pos = existing.start
+				break
+			}
+		}
+		edits = append(edits, textEdit{start: pos, end: pos, text: indent + formatGoImportSpec(spec) + "\n"})
+	}
+
+	render := func(specs []goImportSpec) string {
+		var sb strings.Builder
+		for _, spec := range specs {
+			sb.WriteString(indent + formatGoImportSpec(spec) + "\n")
+		}
+		return sb.String()
+	}
+	if len(newStd) > 0 {
+		edits = append(edits, textEdit{start: first.start, end: first.start, text: render(newStd) + "\n"})
+	}
+	if len(newOther) > 0 {
+		pos := decl.specs[len(decl.specs)-1].end
+		edits = append(edits, textEdit{start: pos, end: pos, text: "\n" + render(newOther)})
+	}
+	return edits
+}
+
+// bestGoImportGroup picks the group a new import joins: the group of the same
+// kind whose first import shares at least two leading path elements with it
+// (the same host and owner), or else the first group of that kind.
+func bestGoImportGroup(groups [][]goImportSpec, path string, std bool) []goImportSpec {
+	var first, best []goImportSpec
+	bestShared := 1
+	for _, group := range groups {
+		if isGoStdImport(group[0].path) != std {
+			continue
+		}
+		if first == nil {
+			first = group
+		}
+		if shared := sharedPathElements(group[0].path, path); shared > bestShared {
+			best, bestShared = group, shared
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return first
+}
+
+func sharedPathElements(a, b string) int {
+	aElems, bElems := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(aElems) && n < len(bElems) && aElems[n] == bElems[n] {
+		n++
+	}
+	return n
+}
+
+func hasBlankLine(text string) bool {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		// The last element is the part of the line the next spec starts on
+		if i < len(lines)-1 && strings.TrimSpace(line) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneGoImports removes imports whose name is not used as a selector
+// anywhere in content, like goimports does but offline: package names are
+// guessed from import paths. Blank, dot and cgo imports are always kept.
+func pruneGoImports(content string) string {
+	imports, err := parseGoImports(content)
+	if err != nil {
+		log.Printf("Предупреждение: не удалось прочитать импорты для удаления неиспользуемых: %v", err)
+		return content
+	}
+	used := goSelectorNames(content)
+
+	var edits []textEdit
+	for _, decl := range imports.decls {
+		var kept []goImportSpec
+		for _, spec := range decl.specs {
+			if spec.name == "_" || spec.name == "." || spec.path == "C" || used[goImportLocalName(spec)] {
+				kept = append(kept, spec)
+				continue
+			}
+			log.Printf("Удалён неиспользуемый импорт %q", spec.path)
+		}
+		if len(kept) == len(decl.specs) {
+			continue
+		}
+		if len(kept) == 0 || !decl.grouped {
+			edits = append(edits, textEdit{start: decl.start, end: decl.end, text: ""})
+			continue
+		}
+
+		// Rebuild the block body from the kept lines and drop the blank lines
+		// that removed groups leave behind
+		body := content[decl.lparen+1 : decl.rparen]
+		bodyStart := decl.lparen + 1
+		var sb strings.Builder
+		last := 0
+		for _, spec := range decl.specs {
+			if containsGoImport(kept, spec) {
+				continue
+			}
+			sb.WriteString(body[last : spec.start-bodyStart])
+			last = spec.end - bodyStart
+		}
+		sb.WriteString(body[last:])
+		newBody := sb.String()
+		for strings.Contains(newBody, "\n\n\n") {
+			newBody = strings.ReplaceAll(newBody, "\n\n\n", "\n\n")
+		}
+		newBody = "\n\t" + strings.Trim(newBody, "\n \t") + "\n"
+		edits = append(edits, textEdit{start: bodyStart, end: decl.rparen, text: newBody})
+	}
+	return applyEdits(content, edits)
+}
+
+func containsGoImport(specs []goImportSpec, spec goImportSpec) bool {
+	for _, candidate := range specs {
+		if candidate == spec {
+			return true
+		}
+	}
+	return false
+}
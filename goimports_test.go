@@ -0,0 +1,240 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMergeGoImports(t *testing.T) {
+	source := `package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	mrand "math/rand"
+
+	"github.com/back2nix/devils/internal/logger"
+)
+
+func Handler(c *fiber.Ctx) error {
+	logger.Errorf("at %v", time.Now())
+	if mrand.Intn(2) == 0 {
+		return errors.New("unlucky")
+	}
+	return nil
+}
+`
+
+	tests := []struct {
+		name     string
+		target   string
+		prune    bool
+		expected string
+	}{
+		{
+			name: "grouped block keeps grouping and sort order",
+			target: `package handlers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/back2nix/devils/internal/models"
+)
+`,
+			expected: `package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	mrand "math/rand"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/back2nix/devils/internal/models"
+)
+`,
+		},
+		{
+			name: "single import becomes a block",
+			target: `package handlers
+
+import "time"
+
+var started = time.Now()
+`,
+			expected: `package handlers
+
+import (
+	"errors"
+	mrand "math/rand"
+	"time"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+var started = time.Now()
+`,
+		},
+		{
+			name: "no imports yet",
+			target: `package handlers
+
+func Other() {}
+`,
+			expected: `package handlers
+
+import (
+	"errors"
+	mrand "math/rand"
+	"time"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Other() {}
+`,
+		},
+		{
+			name: "name taken by another import",
+			target: `package handlers
+
+import (
+	"math/rand"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/gofiber/fiber/v2"
+	mrand "crypto/rand"
+	"time"
+	"errors"
+)
+`,
+			expected: `package handlers
+
+import (
+	"math/rand"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/gofiber/fiber/v2"
+	mrand "crypto/rand"
+	"time"
+	"errors"
+)
+`,
+		},
+		{
+			name:  "prune unused imports",
+			prune: true,
+			target: `package handlers
+
+import (
+	"encoding/json"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Handler(c *fiber.Ctx) error {
+	logger.Errorf("at %v", time.Now())
+	if mrand.Intn(2) == 0 {
+		return errors.New("unlucky")
+	}
+	return nil
+}
+`,
+			expected: `package handlers
+
+import (
+	"errors"
+	mrand "math/rand"
+	"time"
+
+	"github.com/back2nix/devils/internal/logger"
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/lib/pq"
+)
+
+func Handler(c *fiber.Ctx) error {
+	logger.Errorf("at %v", time.Now())
+	if mrand.Intn(2) == 0 {
+		return errors.New("unlucky")
+	}
+	return nil
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacer := NewFunctionReplacer()
+			replacer.PruneImports = tt.prune
+
+			synced, err := langGo.Extract(source)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+			}
+
+			result := replacer.mergeGoImports(tt.target, source, synced)
+			if result != tt.expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMergeGoImports_NewTargetGetsPackageClause(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	source, err := readFile("testdata/user_clipboard_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+	synced, err := langGo.Extract(source)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+
+	merged := replacer.mergeGoImports(replacer.replaceFunctions("", synced, langGo), source, synced)
+	imports, err := parseGoImports(merged)
+	if err != nil {
+		t.Fatalf("Ошибка разбора результата: %v", err)
+	}
+	if imports.packageClause != "package handlers" {
+		t.Errorf("Ожидался package handlers, получен %q", imports.packageClause)
+	}
+	if len(imports.decls) != 1 || len(imports.decls[0].specs) != 10 {
+		t.Errorf("Ожидался один блок из 10 импортов:\n%s", merged)
+	}
+}
+
+func TestGoImportLocalName(t *testing.T) {
+	tests := []struct {
+		spec     goImportSpec
+		expected string
+	}{
+		{spec: goImportSpec{path: "fmt"}, expected: "fmt"},
+		{spec: goImportSpec{path: "encoding/json"}, expected: "json"},
+		{spec: goImportSpec{path: "github.com/gofiber/fiber/v2"}, expected: "fiber"},
+		{spec: goImportSpec{path: "gopkg.in/yaml.v3"}, expected: "yaml"},
+		{spec: goImportSpec{path: "github.com/mattn/go-sqlite3"}, expected: "sqlite3"},
+		{spec: goImportSpec{path: "github.com/back2nix/devils/internal/ws_centrifuge"}, expected: "ws_centrifuge"},
+		{spec: goImportSpec{name: "mrand", path: "math/rand"}, expected: "mrand"},
+	}
+	for _, tt := range tests {
+		if got := goImportLocalName(tt.spec); got != tt.expected {
+			t.Errorf("Для %q ожидалось имя %s, получено %s", tt.spec.path, tt.expected, got)
+		}
+	}
+}
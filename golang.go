@@ -0,0 +1,614 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	goformat "go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// syntheticGoPackage is prepended to clipboard fragments that have no package
+// clause, so that go/parser accepts a bare list of declarations. It takes a
+// line of its own: a comment on the package clause's line would not become
+// the doc comment of the fragment's first declaration.
+const syntheticGoPackage = "package snippet\n"
+
+// parseGoSnippet parses content as a Go file. Fragments without a package
+// clause are retried inside a synthetic one; the returned offset is the number
+// of bytes that were prepended and must be subtracted from file offsets.
+func parseGoSnippet(content string, mode parser.Mode) (*token.FileSet, *ast.File, int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, mode)
+	if err == nil {
+		return fset, file, 0, nil
+	}
+	if hasGoPackageClause(content) {
+		return nil, nil, 0, err
+	}
+
+	fset = token.NewFileSet()
+	file, wrappedErr := parser.ParseFile(fset, "", syntheticGoPackage+content, mode)
+	if wrappedErr != nil {
+		return nil, nil, 0, wrappedErr
+	}
+	return fset, file, len(syntheticGoPackage), nil
+}
+
+// hasGoPackageClause reports whether the first token of content (ignoring
+// comments) is the package keyword.
+func hasGoPackageClause(content string) bool {
+	i := 0
+	for i < len(content) {
+		switch {
+		case content[i] == ' ' || content[i] == '\t' || content[i] == '\n' || content[i] == '\r':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			next := strings.IndexByte(content[i:], '\n')
+			if next == -1 {
+				return false
+			}
+			i += next + 1
+		case strings.HasPrefix(content[i:], "/*"):
+			next := strings.Index(content[i+2:], "*/")
+			if next == -1 {
+				return false
+			}
+			i += next + 4
+		default:
+			return strings.HasPrefix(content[i:], "package") &&
+				(len(content) == i+7 || !isGoIdentChar(content[i+7]))
+		}
+	}
+	return false
+}
+
+func isGoIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// extractGoDeclarationsAST extracts every top-level FuncDecl and every spec of
+// the supported GenDecls with the exact byte range it occupies in content. The
+// range starts at the declaration's doc comment when it has one.
+func extractGoDeclarationsAST(content string) ([]Function, error) {
+	fset, file, prefixLen, err := parseGoSnippet(content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tokFile := fset.File(file.Pos())
+	offset := func(pos token.Pos) int {
+		return tokFile.Offset(pos) - prefixLen
+	}
+
+	var declarations []Function
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			start, end := offset(decl.Pos()), offset(decl.End())
+			if start < 0 || end > len(content) || start >= end {
+				return nil, fmt.Errorf("некорректный диапазон функции %s: %d-%d", decl.Name.Name, start, end)
+			}
+			// The doc group includes directives such as //go:noinline and //nolint
+			docStart := start
+			if decl.Doc != nil {
+				docStart = offset(decl.Doc.Pos())
+			}
+
+			receiver := ""
+			if recv := decl.Recv; recv != nil && recv.Opening.IsValid() && recv.Closing.IsValid() {
+				receiver = strings.TrimSpace(content[offset(recv.Opening)+1 : offset(recv.Closing)])
+			}
+
+			declarations = append(declarations, Function{
+				Name:     decl.Name.Name,
+				Kind:     DeclFunc,
+				Receiver: receiver,
+				Doc:      content[docStart:start],
+				FullText: content[docStart:end],
+				StartPos: docStart,
+				EndPos:   end,
+			})
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE && decl.Tok != token.VAR && decl.Tok != token.CONST {
+				continue
+			}
+			declarations = append(declarations, goGenDeclEntries(content, decl, offset)...)
+		}
+	}
+	return declarations, nil
+}
+
+// goGenDeclEntries turns a type/var/const declaration into one entry per spec.
+// A standalone declaration is a single entry that includes its keyword; specs
+// of a grouped "const (...)" block cover only the spec itself and share a Group.
+func goGenDeclEntries(content string, decl *ast.GenDecl, offset func(token.Pos) int) []Function {
+	kind := DeclKind(decl.Tok.String())
+	var entries []Function
+
+	if !decl.Lparen.IsValid() {
+		if len(decl.Specs) != 1 {
+			return nil
+		}
+		spec := decl.Specs[0]
+		start, end := offset(decl.Pos()), offset(decl.End())
+		if comment := goSpecComment(spec); comment != nil {
+			end = max(end, offset(comment.End()))
+		}
+		docStart := start
+		if decl.Doc != nil {
+			docStart = offset(decl.Doc.Pos())
+		}
+		return append(entries, Function{
+			Name:     goSpecName(spec),
+			Kind:     kind,
+			Doc:      content[docStart:start],
+			FullText: content[docStart:end],
+			StartPos: docStart,
+			EndPos:   end,
+		})
+	}
+
+	group := fmt.Sprintf("%s@%d", kind, offset(decl.Pos()))
+	for _, spec := range decl.Specs {
+		start, end := offset(spec.Pos()), offset(spec.End())
+		if comment := goSpecComment(spec); comment != nil {
+			end = max(end, offset(comment.End()))
+		}
+		docStart := start
+		if doc := goSpecDoc(spec); doc != nil {
+			docStart = offset(doc.Pos())
+		}
+		lineStart := strings.LastIndexByte(content[:docStart], '\n') + 1
+		indent := content[lineStart:docStart]
+		if strings.TrimSpace(indent) != "" {
+			indent = ""
+		}
+		entries = append(entries, Function{
+			Name:     goSpecName(spec),
+			Kind:     kind,
+			Doc:      content[docStart:start],
+			FullText: content[docStart:end],
+			StartPos: docStart,
+			EndPos:   end,
+			Group:    group,
+			Indent:   indent,
+		})
+	}
+	return entries
+}
+
+// goSpecName returns the declared name of a spec; a var/const spec that
+// declares several identifiers is named by all of them, comma-separated.
+func goSpecName(spec ast.Spec) string {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.Name
+	case *ast.ValueSpec:
+		names := make([]string, len(spec.Names))
+		for i, name := range spec.Names {
+			names[i] = name.Name
+		}
+		return strings.Join(names, ",")
+	}
+	return ""
+}
+
+func goSpecDoc(spec ast.Spec) *ast.CommentGroup {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Doc
+	case *ast.ValueSpec:
+		return spec.Doc
+	}
+	return nil
+}
+
+func goSpecComment(spec ast.Spec) *ast.CommentGroup {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Comment
+	case *ast.ValueSpec:
+		return spec.Comment
+	}
+	return nil
+}
+
+// skipGoLiteral returns the index just past the string, rune, raw string or
+// comment that starts at content[i], or i itself when none starts there.
+// Unterminated literals run to the end of their line (or of content for raw
+// strings and block comments), mirroring how the Go scanner recovers.
+func skipGoLiteral(content string, i int) int {
+	if i >= len(content) {
+		return i
+	}
+	switch content[i] {
+	case '"', '\'':
+		quote := content[i]
+		j := i + 1
+		for j < len(content) && content[j] != quote && content[j] != '\n' {
+			if content[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j < len(content) && content[j] == quote {
+			j++
+		}
+		return min(j, len(content))
+	case '`':
+		end := strings.IndexByte(content[i+1:], '`')
+		if end == -1 {
+			return len(content)
+		}
+		return i + 1 + end + 1
+	case '/':
+		if strings.HasPrefix(content[i:], "//") {
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				return len(content)
+			}
+			return i + end
+		}
+		if strings.HasPrefix(content[i:], "/*") {
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				return len(content)
+			}
+			return i + 2 + end + 2
+		}
+	}
+	return i
+}
+
+// goLiteralRanges lists the [start, end) ranges of every string, rune, raw
+// string and comment in content, in order.
+func goLiteralRanges(content string) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < len(content); {
+		if next := skipGoLiteral(content, i); next > i {
+			ranges = append(ranges, [2]int{i, next})
+			i = next
+			continue
+		}
+		i++
+	}
+	return ranges
+}
+
+// insideRanges reports whether pos falls into one of the sorted ranges.
+func insideRanges(ranges [][2]int, pos int) bool {
+	lo, hi := 0, len(ranges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case pos < ranges[mid][0]:
+			hi = mid
+		case pos >= ranges[mid][1]:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// goLeadingCommentStart returns the start of the contiguous block of "//" lines
+// directly above the line at pos, or pos itself when there is none or when pos
+// is not the first token on its line.
+func goLeadingCommentStart(content string, pos int) int {
+	lineStart := strings.LastIndexByte(content[:pos], '\n') + 1
+	if strings.TrimSpace(content[lineStart:pos]) != "" {
+		return pos
+	}
+
+	start := pos
+	for lineStart > 0 {
+		prevStart := strings.LastIndexByte(content[:lineStart-1], '\n') + 1
+		prevLine := strings.TrimSpace(content[prevStart : lineStart-1])
+		if !strings.HasPrefix(prevLine, "//") {
+			break
+		}
+		start = prevStart + strings.Index(content[prevStart:], "//")
+		lineStart = prevStart
+	}
+	return start
+}
+
+// stripGoTypeParams removes every bracketed type parameter list from a
+// receiver, turning "l *List[K, V]" into "l *List".
+func stripGoTypeParams(receiver string) string {
+	var sb strings.Builder
+	depth := 0
+	for i := 0; i < len(receiver); i++ {
+		switch receiver[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		default:
+			if depth == 0 {
+				sb.WriteByte(receiver[i])
+			}
+		}
+	}
+	return sb.String()
+}
+
+// goGenDeclHeaderRegex matches type/var/const declarations that start a line,
+// which is where top-level declarations live in gofmt'ed code.
+var goGenDeclHeaderRegex = regexp.MustCompile(`(?m)^(type|var|const)\b`)
+
+// extractGoGenDeclsLightweight is the fallback for type/var/const declarations
+// in Go snippets that go/parser rejects. Only the given kinds are returned.
+func extractGoGenDeclsLightweight(content string, kinds ...DeclKind) []Function {
+	literalRanges := goLiteralRanges(content)
+	var entries []Function
+	lastEnd := 0
+
+	for _, match := range goGenDeclHeaderRegex.FindAllStringSubmatchIndex(content, -1) {
+		start := match[0]
+		if start < lastEnd || insideRanges(literalRanges, start) {
+			continue
+		}
+		kind := DeclKind(content[match[2]:match[3]])
+
+		i := skipGoSpaces(content, match[1])
+		if i < len(content) && content[i] == '(' {
+			group := fmt.Sprintf("%s@%d", kind, start)
+			i++
+			for {
+				i = skipGoSpacesAndComments(content, i)
+				if i >= len(content) || content[i] == ')' {
+					break
+				}
+				specEnd := goStatementEnd(content, i)
+				if specEnd <= i {
+					break
+				}
+				docStart := goLeadingCommentStart(content, i)
+				lineStart := strings.LastIndexByte(content[:docStart], '\n') + 1
+				entries = append(entries, Function{
+					Name:     goSpecNameFromText(content[i:specEnd]),
+					Kind:     kind,
+					Doc:      content[docStart:i],
+					FullText: content[docStart:specEnd],
+					StartPos: docStart,
+					EndPos:   specEnd,
+					Group:    group,
+					Indent:   content[lineStart:docStart],
+				})
+				i = specEnd
+			}
+			lastEnd = min(i+1, len(content))
+			continue
+		}
+
+		end := goStatementEnd(content, i)
+		if end <= i {
+			continue
+		}
+		docStart := goLeadingCommentStart(content, start)
+		entries = append(entries, Function{
+			Name:     goSpecNameFromText(content[i:end]),
+			Kind:     kind,
+			Doc:      content[docStart:start],
+			FullText: content[docStart:end],
+			StartPos: docStart,
+			EndPos:   end,
+		})
+		lastEnd = end
+	}
+
+	var filtered []Function
+	for _, entry := range entries {
+		for _, kind := range kinds {
+			if entry.Kind == kind {
+				filtered = append(filtered, entry)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// goSpecNameFromText returns the declared name of a spec written as text, in
+// the same form as goSpecName.
+func goSpecNameFromText(spec string) string {
+	var names []string
+	i := 0
+	for {
+		start := i
+		for i < len(spec) && isGoIdentChar(spec[i]) {
+			i++
+		}
+		if i == start {
+			break
+		}
+		names = append(names, spec[start:i])
+		i = skipGoSpaces(spec, i)
+		if i >= len(spec) || spec[i] != ',' {
+			break
+		}
+		i = skipGoSpaces(spec, i+1)
+	}
+	return strings.Join(names, ",")
+}
+
+func skipGoSpaces(content string, i int) int {
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func skipGoSpacesAndComments(content string, i int) int {
+	for i < len(content) {
+		switch {
+		case content[i] == ' ' || content[i] == '\t' || content[i] == '\n' || content[i] == '\r' || content[i] == ';':
+			i++
+		case strings.HasPrefix(content[i:], "//") || strings.HasPrefix(content[i:], "/*"):
+			i = skipGoLiteral(content, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// goStatementEnd returns the end of the spec or declaration starting at i: the
+// first ';', newline or unmatched ')' at nesting depth zero where Go would
+// insert a semicolon. A trailing line comment belongs to the statement.
+func goStatementEnd(content string, i int) int {
+	depth := 0
+	end := i
+	var last byte
+	for i < len(content) {
+		c := content[i]
+		if next := skipGoLiteral(content, i); next > i {
+			if c != '/' {
+				last = c
+				end = next
+			} else if strings.HasPrefix(content[i:], "//") {
+				end = next
+			}
+			i = next
+			continue
+		}
+		switch {
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return end
+			}
+			depth--
+		case c == ';' && depth == 0:
+			return end
+		case c == '\n' && depth == 0:
+			if isGoIdentChar(last) || last == ')' || last == ']' || last == '}' || last == '"' || last == '\'' || last == '`' {
+				return end
+			}
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			last = c
+			end = i + 1
+		}
+		i++
+	}
+	return end
+}
+
+// formatGoSource checks that content, the merged result for a Go target,
+// still parses, and formats it with go/format unless format is false. Syntax
+// errors are reported with the line:col of the first problem. Fragments
+// without a package clause are checked as a list of declarations.
+func formatGoSource(content string, format bool) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return content, nil
+	}
+	if _, _, _, err := parseGoSnippet(content, parser.ParseComments); err != nil {
+		return "", goSyntaxError(err, !hasGoPackageClause(content))
+	}
+	if !format {
+		return content, nil
+	}
+
+	formatted, err := goformat.Source([]byte(content))
+	if err != nil {
+		return "", fmt.Errorf("не удалось отформатировать Go-код: %v", err)
+	}
+	return string(formatted), nil
+}
+
+// goSyntaxError turns a go/parser error into "line:col: message" of its
+// first entry. wrapped tells that the synthetic package clause was prepended,
+// so the line it takes must not count.
+func goSyntaxError(err error, wrapped bool) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		return fmt.Errorf("результат не является корректным Go-кодом: %v", err)
+	}
+	first := list[0]
+	line := first.Pos.Line
+	if wrapped {
+		line = max(line-1, 1)
+	}
+	if len(list) > 1 {
+		return fmt.Errorf("результат не является корректным Go-кодом: %d:%d: %s (и ещё ошибок: %d)", line, first.Pos.Column, first.Msg, len(list)-1)
+	}
+	return fmt.Errorf("результат не является корректным Go-кодом: %d:%d: %s", line, first.Pos.Column, first.Msg)
+}
+
+// goLanguage syncs Go sources: functions, methods keyed by their receiver
+// type, and type/var/const specs.
+type goLanguage struct{}
+
+var langGo = goLanguage{}
+
+func (goLanguage) Name() string         { return "go" }
+func (goLanguage) Family() string       { return "Go" }
+func (goLanguage) Aliases() []string    { return nil }
+func (goLanguage) Extensions() []string { return []string{".go"} }
+
+// goIndicators are the snippets that make content look like Go.
+var goIndicators = []string{"func ", "package ", "import (", "type ", "var ", "go func"}
+
+func (goLanguage) ContentScore(content string) int {
+	return countIndicators(content, goIndicators)
+}
+
+// Extract parses content with go/parser and falls back to the regex and
+// brace balancing extractors for snippets it rejects.
+func (goLanguage) Extract(content string) ([]Function, error) {
+	astDeclarations, err := extractGoDeclarationsAST(content)
+	if err == nil {
+		return astDeclarations, nil
+	}
+	log.Printf("Предупреждение: go/parser не смог разобрать код (%v), используется упрощённый разбор", err)
+	declarations := append(extractGoFunctionsLightweight(content), extractGoGenDeclsLightweight(content, DeclType, DeclVar, DeclConst)...)
+	sort.SliceStable(declarations, func(i, j int) bool {
+		return declarations[i].StartPos < declarations[j].StartPos
+	})
+	return declarations, nil
+}
+
+func (goLanguage) Key(fn Function) string {
+	if fn.Receiver != "" {
+		// Type parameter names are dropped, so List[T] and List[E] share a key
+		receiverParts := strings.Fields(stripGoTypeParams(fn.Receiver))
+		var receiverType string
+		if len(receiverParts) > 0 {
+			receiverType = strings.TrimPrefix(receiverParts[len(receiverParts)-1], "*")
+		}
+		if receiverType != "" {
+			return fmt.Sprintf("%s.%s", receiverType, fn.Name)
+		}
+		return fmt.Sprintf("receiver_%s.%s", strings.ReplaceAll(fn.Receiver, " ", "_"), fn.Name)
+	}
+	if fn.Name == "_" {
+		// Blank specs like "var _ io.Reader = (*T)(nil)" only match when identical
+		_, spec := declParts(fn)
+		return "_ " + strings.Join(strings.Fields(spec), " ")
+	}
+	return fn.Name
+}
+
+// Insert appends every new entry at the end of the file; new specs of grouped
+// blocks are placed by replaceFunctions itself.
+func (goLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	return nil, newFunctions
+}
+
+// Finish adds the imports the synced entries need and formats the result
+// unless fr.NoFormat is set.
+func (goLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	content = fr.mergeGoImports(content, sourceContent, synced)
+	return formatGoSource(content, !fr.NoFormat)
+}
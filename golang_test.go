@@ -0,0 +1,470 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractGoFunctionsAST_ByteRanges(t *testing.T) {
+	for _, filename := range []string{"testdata/go_tricky_signatures.go", "testdata/go_fragment.go", "testdata/source_complex.go"} {
+		t.Run(filename, func(t *testing.T) {
+			content, err := readFile(filename)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать файл %s: %v", filename, err)
+			}
+
+			functions, err := extractGoDeclarationsAST(content)
+			if err != nil {
+				t.Fatalf("Ошибка разбора %s: %v", filename, err)
+			}
+
+			for _, fn := range functions {
+				if got := content[fn.StartPos:fn.EndPos]; got != fn.FullText {
+					t.Errorf("Диапазон %d-%d функции %s не совпадает с FullText:\n%s", fn.StartPos, fn.EndPos, fn.Name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractGoFunctionsAST_Receiver(t *testing.T) {
+	content, err := readFile("testdata/go_fragment.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	functions, err := extractGoDeclarationsAST(content)
+	if err != nil {
+		t.Fatalf("Ошибка разбора: %v", err)
+	}
+	if len(functions) != 2 {
+		t.Fatalf("Ожидалось 2 функции, получено %d", len(functions))
+	}
+	if functions[1].Receiver != "s *Service" {
+		t.Errorf("Ожидался receiver 's *Service', получен '%s'", functions[1].Receiver)
+	}
+	if !hasGoPackageClause("// comment\npackage main\n") || hasGoPackageClause(content) {
+		t.Error("hasGoPackageClause вернул неверный результат")
+	}
+}
+
+func TestReplaceFunctions_GoFragmentDoc(t *testing.T) {
+	sourceContent, err := readFile("testdata/go_doc_fragment.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_doc_fragment.go: %v", err)
+	}
+	targetContent, err := readFile("testdata/go_doc_target.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_doc_target.go: %v", err)
+	}
+
+	replacer := NewFunctionReplacer()
+	sourceFunctions, err := langGo.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций из фрагмента: %v", err)
+	}
+	if len(sourceFunctions) != 1 || !strings.HasPrefix(sourceFunctions[0].Doc, "// Documented prints a greeting.") {
+		t.Fatalf("Комментарий в начале фрагмента не попал в Doc: %+v", sourceFunctions)
+	}
+
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langGo)
+	want := "// Documented prints a greeting.\n// Updated doc from fragment.\nfunc Documented() {\n\tfmt.Println(\"Documented body from fragment\")"
+	if !strings.Contains(result, want) {
+		t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+	}
+	if strings.Contains(result, "Stale doc from target") {
+		t.Errorf("Устаревший комментарий цели не заменён:\n%s", result)
+	}
+}
+
+func TestExtractGoFunctionsLightweight_Literals(t *testing.T) {
+	content, err := readFile("testdata/go_raw_strings_fragment.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+	if _, err := extractGoDeclarationsAST(content); err == nil {
+		t.Fatal("Ожидалась ошибка go/parser для фрагмента с заполнителем")
+	}
+
+	functions, err := langGo.Extract(content)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций: %v", err)
+	}
+
+	expected := map[string]string{
+		"QueryTemplate": "lone \" here`\n}",
+		"RuneQuote":     "r == '\\''\n}",
+		"JSONBody":      "{\"id\": 2}]`)\n}",
+		"Usage":         "\"func NotAFunction() {\"\n}",
+	}
+	if len(functions) != len(expected) {
+		t.Fatalf("Ожидалось %d функций, получено %d", len(expected), len(functions))
+	}
+	for _, fn := range functions {
+		suffix, ok := expected[fn.Name]
+		if !ok {
+			t.Errorf("Неожиданная функция %s", fn.Name)
+			continue
+		}
+		if !strings.HasSuffix(fn.FullText, suffix) {
+			t.Errorf("Функция %s обрезана неверно:\n%s", fn.Name, fn.FullText)
+		}
+		if content[fn.StartPos:fn.EndPos] != fn.FullText {
+			t.Errorf("Диапазон функции %s не совпадает с FullText", fn.Name)
+		}
+	}
+}
+
+func TestExtractGoFunctionWithBraceBalancing_Signatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty interface parameter", content: "func F(x interface{}) error {\n\treturn nil\n}"},
+		{name: "anonymous struct result", content: "func G() struct{ a int } {\n\treturn struct{ a int }{}\n}"},
+		{name: "func type parameter", content: "func H(cb func(struct{}) bool) {\n\tcb(struct{}{})\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, end, err := extractGoFunctionWithBraceBalancing(tt.content+"\n\nvar after = 1", 0)
+			if err != nil {
+				t.Fatalf("Ошибка: %v", err)
+			}
+			if text != tt.content || end != len(tt.content) {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", tt.content, text)
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_GoDocComments(t *testing.T) {
+	sourceContent, err := readFile("testdata/go_doc_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_doc_source.go: %v", err)
+	}
+	targetContent, err := readFile("testdata/go_doc_target.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_doc_target.go: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		keepTargetDoc    bool
+		shouldContain    []string
+		shouldNotContain []string
+	}{
+		{
+			name: "doc travels with the body",
+			shouldContain: []string{
+				"// Updated doc from source.\n//\n//go:noinline\nfunc Documented() {\n\tfmt.Println(\"Documented body from source\")",
+				"// Undocumented keeps this doc from target.\nfunc Undocumented() {\n\tfmt.Println(\"Undocumented body from source\")",
+				"//export ExportedHelper\n//nolint:unused\nfunc ExportedHelper() {\n\tfmt.Println(\"ExportedHelper body from source\")",
+			},
+			shouldNotContain: []string{"Stale doc from target", "body from target"},
+		},
+		{
+			name:          "keep target doc",
+			keepTargetDoc: true,
+			shouldContain: []string{
+				"// Stale doc from target.\nfunc Documented() {\n\tfmt.Println(\"Documented body from source\")",
+				"// Undocumented keeps this doc from target.\nfunc Undocumented() {",
+				"\n\nfunc ExportedHelper() {\n\tfmt.Println(\"ExportedHelper body from source\")",
+			},
+			shouldNotContain: []string{"Updated doc from source", "//go:noinline", "//export", "body from target"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacer := NewFunctionReplacer()
+			replacer.KeepTargetDoc = tt.keepTargetDoc
+
+			sourceFunctions, err := langGo.Extract(sourceContent)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
+			}
+			result := replacer.replaceFunctions(targetContent, sourceFunctions, langGo)
+
+			for _, want := range tt.shouldContain {
+				if !strings.Contains(result, want) {
+					t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+				}
+			}
+			for _, unwanted := range tt.shouldNotContain {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("Найдена нежелательная строка '%s'", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestGoLeadingCommentStart(t *testing.T) {
+	content := "var x = 1\n\n// Doc line one.\n//go:noinline\nfunc F() {}\n"
+	pos := strings.Index(content, "func F")
+	if got := content[goLeadingCommentStart(content, pos):pos]; got != "// Doc line one.\n//go:noinline\n" {
+		t.Errorf("Неверный doc-комментарий: %q", got)
+	}
+
+	inline := "/* not a doc */ func G() {}"
+	pos = strings.Index(inline, "func G")
+	if got := goLeadingCommentStart(inline, pos); got != pos {
+		t.Errorf("Ожидалось отсутствие doc-комментария, получено начало %d", got)
+	}
+}
+
+func TestExtractFunctions_GoGenerics(t *testing.T) {
+	content, err := readFile("testdata/go_generics_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	expectedKeys := []string{"Map", "Filter", "List.Push", "Pair.Key"}
+	extractors := map[string]func(string) []Function{
+		"ast": func(content string) []Function {
+			functions, err := extractGoDeclarationsAST(content)
+			if err != nil {
+				t.Fatalf("Ошибка разбора: %v", err)
+			}
+			return functions
+		},
+		"lightweight": extractGoFunctionsLightweight,
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			functions := extract(content)
+			if len(functions) != len(expectedKeys) {
+				t.Fatalf("Ожидалось %d функций, получено %d", len(expectedKeys), len(functions))
+			}
+			for i, fn := range functions {
+				if key := langGo.Key(fn); key != expectedKeys[i] {
+					t.Errorf("Ожидался ключ [%d] %s, получен %s", i, expectedKeys[i], key)
+				}
+				if !strings.HasSuffix(fn.FullText, "from source\n}") {
+					t.Errorf("Функция %s обрезана неверно:\n%s", fn.Name, fn.FullText)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_GoGenerics(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/go_generics_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_generics_source.go: %v", err)
+	}
+	targetContent, err := readFile("testdata/go_generics_target.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_generics_target.go: %v", err)
+	}
+
+	sourceFunctions, err := langGo.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langGo)
+
+	for _, name := range []string{"Map", "Filter", "Push", "Key"} {
+		if !strings.Contains(result, name+" from source") {
+			t.Errorf("%s не была заменена или добавлена", name)
+		}
+		if strings.Contains(result, name+" from target") {
+			t.Errorf("Старая версия %s осталась в результате", name)
+		}
+	}
+	if strings.Count(result, ") Push(") != 1 {
+		t.Errorf("Метод Push продублирован:\n%s", result)
+	}
+}
+
+func TestReplaceFunctions_GoTypes(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/go_types_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_types_source.go: %v", err)
+	}
+	targetContent, err := readFile("testdata/go_types_target.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_types_target.go: %v", err)
+	}
+
+	sourceDeclarations, err := langGo.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langGo)
+
+	shouldContain := []string{
+		"type (\n\t// Request is the request payload.\n\tRequest struct {\n\t\tID   string `json:\"id\"`\n\t\tMode int    `json:\"mode\"` // Mode from source\n\t}\n\n\tKeep int\n)",
+		"// Handler handles requests.\ntype Handler interface {\n\tHandle(r Request) error // Handler from source\n}",
+		"type ID = string // alias from source\n",
+		"\ntype NewSourceOnlyType struct{ Value int }\n",
+		"UseRequest from source",
+	}
+	for _, want := range shouldContain {
+		if !strings.Contains(result, want) {
+			t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+		}
+	}
+	for _, unwanted := range []string{"stale request payload", "from target"} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("Найдена нежелательная строка '%s'", unwanted)
+		}
+	}
+}
+
+func TestExtractGoGenDeclsLightweight_MatchesAST(t *testing.T) {
+	for _, filename := range []string{"testdata/go_types_source.go", "testdata/go_types_target.go", "testdata/go_values_source.go", "testdata/go_values_target.go", "testdata/no_funcs.go"} {
+		t.Run(filename, func(t *testing.T) {
+			content, err := readFile(filename)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать файл %s: %v", filename, err)
+			}
+			astDeclarations, err := extractGoDeclarationsAST(content)
+			if err != nil {
+				t.Fatalf("Ошибка разбора %s: %v", filename, err)
+			}
+			var expected []Function
+			for _, decl := range astDeclarations {
+				if decl.Kind != DeclFunc {
+					expected = append(expected, decl)
+				}
+			}
+
+			got := extractGoGenDeclsLightweight(content, DeclType, DeclVar, DeclConst)
+			if len(got) != len(expected) {
+				t.Fatalf("Ожидалось %d объявлений, получено %d", len(expected), len(got))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], expected[i]) {
+					t.Errorf("Объявление [%d] отличается:\nупрощённый разбор: %+v\ngo/parser:         %+v", i, got[i], expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_GoValues(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/go_values_source.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_values_source.go: %v", err)
+	}
+	targetContent, err := readFile("testdata/go_values_target.go")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать go_values_target.go: %v", err)
+	}
+
+	sourceDeclarations, err := langGo.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langGo)
+
+	shouldContain := []string{
+		"var (\n\tErrNotFound = errors.New(\"not found from source\")\n\tErrOther    = errors.New(\"other\")\n)",
+		"// Prices for premium sessions: duration_in_seconds -> price_in_cents\nvar premiumSessionPrices = map[int]int64{\n\t300: 1800,\n\t600: 3200,\n\t900: 4500, // added in source\n}",
+		"const (\n\tStateIdle State = iota\n\tStateRunning\n\tStateStopped // new from source\n)",
+		"\nvar ErrNewFromSource = errors.New(\"new error from source\")\n",
+		"\nconst (\n\tfiveMinutes = 300\n\ttenMinutes  = 600 // new group from source\n)\n",
+		"\nvar _ fmt.Stringer = (*Thing)(nil)\n",
+	}
+	for _, want := range shouldContain {
+		if !strings.Contains(result, want) {
+			t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "not found from target") {
+		t.Error("Старое значение ErrNotFound осталось в результате")
+	}
+
+	if again := replacer.replaceFunctions(result, sourceDeclarations, langGo); again != result {
+		t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+	}
+}
+
+func TestGoSpecNameFromText(t *testing.T) {
+	tests := map[string]string{
+		"x = 1":                      "x",
+		"a, b int = 1, 2":            "a,b",
+		"List[T any] struct{}":       "List",
+		"_ fmt.Stringer = (*T)(nil)": "_",
+	}
+	for spec, expected := range tests {
+		if got := goSpecNameFromText(spec); got != expected {
+			t.Errorf("Для '%s' ожидалось имя '%s', получено '%s'", spec, expected, got)
+		}
+	}
+}
+
+func TestFormatGoSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		format      bool
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "formats a valid file",
+			content:  "package main\n\nfunc  F( ) {\nreturn\n}\n",
+			format:   true,
+			expected: "package main\n\nfunc F() {\n\treturn\n}\n",
+		},
+		{
+			name:     "formatting disabled keeps bytes",
+			content:  "package main\n\nfunc  F( ) {\nreturn\n}\n",
+			format:   false,
+			expected: "package main\n\nfunc  F( ) {\nreturn\n}\n",
+		},
+		{
+			name:     "fragment without package clause",
+			content:  "func  F( ) {\nreturn\n}\n",
+			format:   true,
+			expected: "func F() {\n\treturn\n}\n",
+		},
+		{
+			name:        "broken merge is rejected with line and column",
+			content:     "package main\n\nfunc F() {\n\treturn\n\nfunc G() {}\n",
+			format:      true,
+			expectedErr: "6:6: expected '(', found G",
+		},
+		{
+			name:        "column on the first line of a fragment",
+			content:     "func F() { return ) }\n",
+			format:      false,
+			expectedErr: "1:19:",
+		},
+		{
+			name:        "line inside a fragment",
+			content:     "func F() {\n\treturn\n\nfunc G() {}\n",
+			format:      false,
+			expectedErr: "4:6: expected '(', found G",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := formatGoSource(tt.content, tt.format)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Ожидалась ошибка с '%s', получено: %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Неожиданная ошибка: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Ожидалось:\n%q\nполучено:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
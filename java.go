@@ -0,0 +1,567 @@
+package main
+
+import "strings"
+
+// javaDialect tells the class-based languages apart that share a parser.
+type javaDialect int
+
+const (
+	dialectJava javaDialect = iota
+	dialectCSharp
+	dialectKotlin
+)
+
+// javaModifiers may precede a type or member declaration in any dialect.
+var javaModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "internal": true, "static": true,
+	"abstract": true, "final": true, "sealed": true, "partial": true, "readonly": true,
+	"unsafe": true, "new": true, "virtual": true, "override": true, "async": true,
+	"extern": true, "synchronized": true, "native": true, "strictfp": true, "default": true,
+	"transient": true, "volatile": true, "required": true, "file": true,
+	// Kotlin
+	"open": true, "data": true, "inner": true, "annotation": true, "companion": true,
+	"inline": true, "value": true, "suspend": true, "operator": true, "infix": true,
+	"tailrec": true, "external": true, "lateinit": true, "const": true, "actual": true,
+	"expect": true, "enum": true,
+}
+
+// javaParamModifiers are dropped from parameters when building a signature;
+// they do not tell overloads apart.
+var javaParamModifiers = map[string]bool{
+	"final": true, "this": true, "params": true, "scoped": true,
+	"crossinline": true, "noinline": true, "val": true, "var": true,
+}
+
+// jvParser recognizes the types, methods and constructors of a Java, C# or
+// Kotlin source.
+type jvParser struct {
+	*clParser
+	dialect javaDialect
+}
+
+// extractJavaDeclarations returns the classes, interfaces, enums, records
+// and objects of content, nested ones included, and their methods and
+// constructors. Methods carry their parameter types in Signature, which
+// tells overloads apart; Kotlin extension functions carry their
+// receiver type in Receiver.
+func extractJavaDeclarations(content string, dialect javaDialect, syntax *cLikeSyntax) []Function {
+	p := &jvParser{clParser: newCLikeParser(content, syntax), dialect: dialect}
+	p.parseMembers(0, len(p.tokens), "", "")
+	return p.decls
+}
+
+// skipAnnotation returns the index just past the Java or Kotlin annotation,
+// such as @Inject or @field:Json(name = "id"), or the C# attribute list
+// starting at i, or i when there is none.
+func (p *jvParser) skipAnnotation(i, end int) int {
+	if p.dialect == dialectCSharp {
+		if p.is(i, "[") {
+			return p.closing(i, end) + 1
+		}
+		return i
+	}
+	if !p.is(i, "@") || !p.isIdent(i+1) || p.is(i+1, "interface") {
+		return i
+	}
+	j := i + 2
+	for (p.is(j, ".") || p.is(j, ":")) && p.isIdent(j+1) {
+		j += 2
+	}
+	if p.is(j, "(") && !p.tokens[j].newline {
+		j = p.closing(j, end) + 1
+	}
+	return j
+}
+
+// parseMembers walks the declarations in tokens[from:to]. className is the
+// simple name of the enclosing type, which constructors repeat in Java and
+// C#.
+func (p *jvParser) parseMembers(from, to int, container, className string) {
+	for i := from; i < to; {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+		k := i
+		for next := p.skipAnnotation(k, to); next > k; next = p.skipAnnotation(k, to) {
+			k = next
+		}
+		enum := false
+		for p.isIdent(k) && javaModifiers[p.tokens[k].text] && (p.tokens[k].text != "enum" || p.dialect == dialectKotlin) && !p.is(k+1, "=") && !p.is(k+1, ":") && !p.is(k+1, ";") {
+			if p.tokens[k].text == "enum" {
+				enum = true
+			}
+			k++
+			for next := p.skipAnnotation(k, to); next > k; next = p.skipAnnotation(k, to) {
+				k = next
+			}
+		}
+		if k >= to {
+			break
+		}
+
+		next := -1
+		switch keyword := p.tokens[k].text; {
+		case p.tokens[k].kind != clIdent && !p.is(k, "@") && !p.is(k, "~") && !p.is(k, "<") && !p.is(k, "("):
+		case keyword == "class" || keyword == "interface" || keyword == "struct" || keyword == "object" && p.dialect == dialectKotlin ||
+			keyword == "record" && p.dialect != dialectKotlin || keyword == "enum" && p.dialect != dialectKotlin:
+			if keyword == "enum" {
+				enum = true
+			}
+			if keyword == "record" && (p.is(k+1, "struct") || p.is(k+1, "class")) {
+				k++
+			}
+			next = p.parseType(i, k, to, container, enum)
+		case p.is(k, "@") && p.is(k+1, "interface"):
+			next = p.parseType(i, k+1, to, container, false)
+		case keyword == "fun" && p.is(k+1, "interface"):
+			next = p.parseType(i, k+1, to, container, false)
+		case p.dialect == dialectKotlin && (keyword == "fun" || keyword == "constructor" && p.is(k+1, "(")):
+			next = p.parseKotlinFunction(i, k, to, container)
+		case p.dialect == dialectKotlin:
+		case keyword == "namespace":
+			next = p.parseNamespace(i, k, to, container)
+		case keyword == "package" || keyword == "import" || keyword == "using" || keyword == "delegate" || keyword == "event":
+		default:
+			next = p.parseMethod(i, k, to, container, className)
+		}
+		if next > i {
+			i = next
+			continue
+		}
+		i = p.skipMember(k, to)
+	}
+}
+
+// skipMember returns the index just past the field, property, initializer
+// or other member at i.
+func (p *jvParser) skipMember(i, end int) int {
+	if p.dialect == dialectKotlin {
+		return p.kotlinStatementEnd(i, end) + 1
+	}
+	assigned := false
+	for j := i; j < end; j++ {
+		switch {
+		case p.is(j, ";"):
+			return j + 1
+		case p.is(j, "="):
+			assigned = true
+		case p.is(j, "{") && !assigned:
+			// A property may go on with an initializer
+			j = p.closing(j, end)
+			if !p.is(j+1, "=") {
+				return j + 1
+			}
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	return end
+}
+
+// kotlinStatementEnd returns the index of the last token of the Kotlin
+// statement starting at i. Kotlin needs no semicolons, so a statement ends
+// at a line break unless the line ends with an operator or the next one
+// starts with one, as in a ".map { ... }" chain.
+func (p *jvParser) kotlinStatementEnd(i, end int) int {
+	j := i
+	for {
+		if p.isOpen(j) {
+			j = p.closing(j, end)
+		}
+		next := j + 1
+		if next >= end || p.is(next, ";") {
+			return min(j, end-1)
+		}
+		if p.tokens[next].newline && !p.kotlinContinues(j, next) {
+			return j
+		}
+		j = next
+	}
+}
+
+// kotlinContinues tells whether the statement with token prev at the end of
+// a line goes on with token next on the following line.
+func (p *jvParser) kotlinContinues(prev, next int) bool {
+	if tok := p.tokens[prev]; tok.kind == clPunct && strings.Contains("=+-*/%&|,.:(", tok.text) {
+		return true
+	}
+	switch p.tokens[next].text {
+	case ".", "?", ":", "=", "{", "&", "|", "+", "*", "/", "%", "where", "as":
+		return true
+	}
+	return false
+}
+
+// parseType handles the class, interface, enum, record, struct or object
+// whose keyword is at k and whose annotations and modifiers start at first.
+// Members of its body are parsed with the type as container; the constants
+// of an enum are skipped.
+func (p *jvParser) parseType(first, k, end int, container string, enum bool) int {
+	name := "Companion"
+	nameIdx := k
+	if p.isIdent(k + 1) {
+		nameIdx = k + 1
+		name = p.tokens[nameIdx].text
+	} else if p.tokens[k].text != "object" {
+		return -1
+	}
+
+	last := end - 1
+	if p.dialect == dialectKotlin {
+		last = p.kotlinStatementEnd(k, end)
+	}
+	open := -1
+	for j := nameIdx + 1; j <= last; j++ {
+		if p.is(j, "{") {
+			open = j
+			break
+		}
+		if p.is(j, ";") {
+			last = j
+			break
+		}
+		switch {
+		case p.is(j, "<"):
+			j = p.skipAngles(j, end) - 1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	if open != -1 {
+		last = p.closing(open, end)
+	}
+	p.addDecl(name, container, DeclClass, first, last)
+	if open == -1 {
+		return last + 1
+	}
+
+	body := open + 1
+	if enum {
+		body = last
+		for j := open + 1; j < last; j++ {
+			if p.is(j, ";") {
+				body = j + 1
+				break
+			}
+			if p.isOpen(j) {
+				j = p.closing(j, last)
+			}
+		}
+	}
+	p.parseMembers(body, last, qualify(container, name), name)
+	return last + 1
+}
+
+// parseNamespace handles a C# "namespace A.B { ... }" whose keyword is at k.
+// A file-scoped "namespace A.B;" adds nothing.
+func (p *jvParser) parseNamespace(first, k, end int, container string) int {
+	j := k + 1
+	for j < end && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	if !p.is(j, "{") {
+		return j + 1
+	}
+	name := p.render(k+1, j)
+	last := p.closing(j, end)
+	p.addDecl(name, container, DeclNamespace, first, last)
+	p.parseMembers(j+1, last, qualify(container, name), "")
+	return last + 1
+}
+
+// parseMethod handles a Java or C# method or constructor whose return type,
+// or name for a constructor, is at k. Other members, such as fields and
+// properties, are left to the caller.
+func (p *jvParser) parseMethod(first, k, end int, container, className string) int {
+	open := -1
+	nameEnd := -1
+	operator := -1
+	for j := k; j < end && open == -1; j++ {
+		switch {
+		case p.is(j, "(") && j == k:
+			// A C# tuple return type
+			j = p.closing(j, end)
+		case p.is(j, "("):
+			open = j
+			if nameEnd == -1 {
+				nameEnd = j
+			}
+		case p.is(j, "{") || p.is(j, ";") || p.is(j, "=") || p.is(j, ","):
+			return -1
+		case p.is(j, "operator"):
+			operator = j
+		case p.is(j, "<"):
+			after := p.skipAngles(j, end)
+			if p.is(after, "(") && operator == -1 {
+				// Generic arguments of a C# method follow its name
+				nameEnd = j
+			}
+			j = after - 1
+		case p.skipAnnotation(j, end) > j:
+			j = p.skipAnnotation(j, end) - 1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	if open == -1 {
+		return -1
+	}
+
+	var name string
+	nameStart := nameEnd - 1
+	if operator != -1 {
+		nameStart = operator
+		name = "operator " + p.render(operator+1, nameEnd)
+	} else {
+		if !p.isIdent(nameStart) {
+			return -1
+		}
+		for p.is(nameStart-1, ".") && p.isIdent(nameStart-2) {
+			// Explicit interface implementation, e.g. IDisposable.Dispose
+			nameStart -= 2
+		}
+		if p.is(nameStart-1, "~") {
+			nameStart--
+		}
+		name = p.render(nameStart, nameEnd)
+	}
+	if nameStart == k && name != className && !strings.HasPrefix(name, "~") {
+		// Neither a return type nor a constructor: a statement
+		return -1
+	}
+
+	closeParen := p.closing(open, end)
+	last := -1
+	for j := closeParen + 1; j < end && last == -1; j++ {
+		switch {
+		case p.is(j, "{"):
+			last = p.closing(j, end)
+		case p.is(j, ";"):
+			last = j
+		case p.is(j, "=") && p.is(j+1, ">"):
+			for j += 2; j < end && !p.is(j, ";"); j++ {
+				if p.isOpen(j) {
+					j = p.closing(j, end)
+				}
+			}
+			last = min(j, end-1)
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	if last == -1 {
+		return -1
+	}
+	fn := p.addDecl(name, container, DeclFunc, first, last)
+	fn.Signature = p.signature(open, closeParen)
+	return last + 1
+}
+
+// parseKotlinFunction handles "fun <T> Receiver.name(...)" or a secondary
+// "constructor(...)" whose keyword is at k.
+func (p *jvParser) parseKotlinFunction(first, k, end int, container string) int {
+	start := k + 1
+	if p.is(start, "<") {
+		start = p.skipAngles(start, end)
+	}
+	open := k + 1
+	if p.tokens[k].text == "fun" {
+		open = -1
+		for j := start; j < end && open == -1; j++ {
+			switch {
+			case p.is(j, "("):
+				open = j
+			case p.is(j, "{") || p.is(j, "=") || p.is(j, ";"):
+				return -1
+			case p.is(j, "<"):
+				j = p.skipAngles(j, end) - 1
+			case p.isOpen(j):
+				j = p.closing(j, end)
+			}
+		}
+		if open == -1 || !p.isIdent(open-1) {
+			return -1
+		}
+	}
+
+	name := p.tokens[open-1].text
+	receiver := ""
+	if open-2 > start && p.is(open-2, ".") {
+		receiver = p.render(start, open-2)
+	}
+	last := p.kotlinStatementEnd(k, end)
+	fn := p.addDecl(name, container, DeclFunc, first, last)
+	fn.Receiver = receiver
+	fn.Signature = p.signature(open, p.closing(open, end))
+	return last + 1
+}
+
+// signature renders the parameter types of the list between the
+// parentheses at open and closeParen, e.g. "String, List<User>".
+func (p *jvParser) signature(open, closeParen int) string {
+	var types []string
+	start := open + 1
+	defaulted := false
+	for j := open + 1; j <= closeParen; j++ {
+		switch {
+		case j == closeParen || p.is(j, ","):
+			if start < j {
+				types = append(types, p.paramType(start, j))
+			}
+			start = j + 1
+			defaulted = false
+		case p.is(j, "="):
+			defaulted = true
+		case p.is(j, "<") && !defaulted:
+			j = min(p.skipAngles(j, closeParen), closeParen) - 1
+		case p.isOpen(j):
+			j = p.closing(j, closeParen)
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// paramType renders the type of the parameter in tokens[from:to] without
+// its name, annotations, modifiers and default value.
+func (p *jvParser) paramType(from, to int) string {
+	for {
+		if next := p.skipAnnotation(from, to); next > from {
+			from = next
+			continue
+		}
+		if p.isIdent(from) && javaParamModifiers[p.tokens[from].text] && from+1 < to {
+			from++
+			continue
+		}
+		break
+	}
+	for j := from; j < to; j++ {
+		if p.is(j, "=") {
+			to = j
+			break
+		}
+		if p.isOpen(j) {
+			j = p.closing(j, to)
+		}
+	}
+	if from >= to {
+		return ""
+	}
+
+	if p.dialect == dialectKotlin {
+		prefix := ""
+		if p.is(from, "vararg") {
+			prefix = "vararg "
+			from++
+		}
+		for j := from; j < to; j++ {
+			if p.is(j, ":") {
+				return prefix + p.render(j+1, to)
+			}
+		}
+		return prefix + p.render(from, to)
+	}
+
+	// The name is the last identifier; Java allows "int values[]"
+	suffix := ""
+	nameIdx := to - 1
+	for p.is(nameIdx, "]") && p.is(nameIdx-1, "[") {
+		suffix += "[]"
+		nameIdx -= 2
+	}
+	if nameIdx <= from || !p.isIdent(nameIdx) {
+		return p.render(from, to)
+	}
+	return p.render(from, nameIdx) + suffix
+}
+
+// javaLanguage syncs Java, C# or Kotlin sources, where methods live in
+// classes and may be overloaded.
+type javaLanguage struct {
+	name       string
+	family     string
+	aliases    []string
+	extensions []string
+	indicators []string
+	dialect    javaDialect
+	syntax     cLikeSyntax
+}
+
+var (
+	langJava = &javaLanguage{
+		name:       "java",
+		family:     "Java",
+		extensions: []string{".java"},
+		indicators: []string{"import java.", "package ", "public class ", "system.out.", "@override", "public static void", "throws ", "private final ", "string[] ", "new arraylist", "public void ", "private void ", "public int ", "public string ", "public boolean ", "private static final ", "this::"},
+		dialect:    dialectJava,
+		syntax:     cLikeSyntax{textBlocks: true},
+	}
+	langCSharp = &javaLanguage{
+		name:       "csharp",
+		family:     "C#",
+		aliases:    []string{"cs", "c#"},
+		extensions: []string{".cs"},
+		indicators: []string{"using system", "namespace ", "{ get;", "set; }", "task<", "console.", "public override ", "private readonly ", "ienumerable<", "nameof("},
+		dialect:    dialectCSharp,
+		syntax:     cLikeSyntax{textBlocks: true, rawTextBlocks: true, csharpStrings: true},
+	}
+	langKotlin = &javaLanguage{
+		name:       "kotlin",
+		family:     "Kotlin",
+		aliases:    []string{"kt"},
+		extensions: []string{".kt", ".kts"},
+		indicators: []string{"fun ", "val ", "import kotlin", "data class ", "companion object", "override fun", ": unit", "?.let", "listof("},
+		dialect:    dialectKotlin,
+		syntax:     cLikeSyntax{nestedComments: true, textBlocks: true, rawTextBlocks: true, dollarHoles: true},
+	}
+)
+
+func (l *javaLanguage) Name() string         { return l.name }
+func (l *javaLanguage) Family() string       { return l.family }
+func (l *javaLanguage) Aliases() []string    { return l.aliases }
+func (l *javaLanguage) Extensions() []string { return l.extensions }
+
+func (l *javaLanguage) ContentScore(content string) int {
+	return countIndicators(content, l.indicators)
+}
+
+func (l *javaLanguage) Extract(content string) ([]Function, error) {
+	return extractJavaDeclarations(content, l.dialect, &l.syntax), nil
+}
+
+// Key qualifies members with their types, e.g. "UserRepository.save", and
+// Kotlin extension functions with their receiver type.
+func (l *javaLanguage) Key(fn Function) string {
+	name := fn.Name
+	if fn.Kind == DeclFunc && fn.Receiver != "" {
+		name = fn.Receiver + "." + name
+	}
+	return qualify(fn.Container, name)
+}
+
+// Insert places new members before the closing brace of their type. A type
+// without a body, such as a Kotlin "class Id(val value: String)" or a
+// "record Point(int X, int Y);", gets one.
+func (l *javaLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	insertions, rest := blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions)
+
+	var braced []memberInsertion
+	var edits []textEdit
+	for _, ins := range insertions {
+		if strings.HasSuffix(ins.block.FullText, "}") {
+			braced = append(braced, ins)
+			continue
+		}
+		start := ins.block.EndPos
+		if strings.HasSuffix(ins.block.FullText, ";") {
+			start--
+		}
+		edits = append(edits, textEdit{start: start, end: ins.block.EndPos, text: " {\n" + ins.text() + "\n" + ins.block.Indent + "}"})
+	}
+	return append(edits, braceMemberEdits(targetContent, braced)...), rest
+}
+
+func (l *javaLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	return content, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractJavaDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		content  string
+		expected []string
+	}{
+		{
+			name:     "Перегрузки и конструктор Java",
+			lang:     langJava,
+			content:  "class A {\n  A(int x) {}\n  void save(User u) {}\n  void save(java.util.List<User> us) {}\n  <T> T find(Class<T> type, final String... names) { return null; }\n  int sum(int values[]) { return 0; }\n}\n",
+			expected: []string{"A", "A.A(int)", "A.save(User)", "A.save(java.util.List<User>)", "A.find(Class<T>, String...)", "A.sum(int[])"},
+		},
+		{
+			name:     "Поля, инициализаторы и анонимные классы Java пропускаются",
+			lang:     langJava,
+			content:  "class A {\n  static { init(); }\n  Runnable r = new Runnable() { public void run() {} };\n  int[] xs = {1, 2};\n  String s = \"}\";\n  char c = '{';\n  @Override public String toString() { return \"\"; }\n}\n",
+			expected: []string{"A", "A.toString()"},
+		},
+		{
+			name:     "Перечисление и тип аннотации Java",
+			lang:     langJava,
+			content:  "enum E {\n  A { void f() {} }, B;\n  void g() {}\n}\n@interface Tag {\n  String value() default \"\";\n}\nrecord P(int x, int y) {}\n",
+			expected: []string{"E", "E.g()", "Tag", "Tag.value()", "P"},
+		},
+		{
+			name: "Члены классов C#",
+			lang: langCSharp,
+			content: "namespace Shop {\n  class A : IDisposable {\n    public int Count { get; set; } = 0;\n    public string this[int i] => \"\";\n" +
+				"    public delegate void Handler(int x);\n    public A(int x) : base(x) {}\n    ~A() {}\n    public Task<int> Get<T>(int id, CancellationToken ct = default) where T : new() => null;\n" +
+				"    public (int, string) Split(ref int x, out string y, params object[] rest) { y = $\"{x}}}\"; return (x, y); }\n" +
+				"    void IDisposable.Dispose() {}\n    public static A operator +(A a, A b) => a;\n    string path = @\"C:\\{\";\n  }\n}\n",
+			expected: []string{"Shop", "Shop.A", "Shop.A.A(int)", "Shop.A.~A()", "Shop.A.Get(int, CancellationToken)", "Shop.A.Split(ref int, out string, object[])", "Shop.A.IDisposable.Dispose()", "Shop.A.operator +(A, A)"},
+		},
+		{
+			name:     "Пространство имён C# на весь файл",
+			lang:     langCSharp,
+			content:  "namespace Shop;\n\n[Serializable]\npublic record Line(string Sku);\n\npublic struct Totals { public decimal Sum() => 0; }\n",
+			expected: []string{"Line", "Totals", "Totals.Sum()"},
+		},
+		{
+			name: "Функции и объекты Kotlin",
+			lang: langKotlin,
+			content: "class A(val x: Int) {\n  val size: Int\n    get() = x\n  init { println(\"}\") }\n  constructor() : this(0)\n" +
+				"  fun f(a: Int = 1, vararg b: String) = a\n    .plus(1)\n  fun g(cb: (Int) -> Unit) {}\n  companion object {\n    fun make() = A(1)\n  }\n}\n" +
+				"fun <T> List<T>.second(): T = this[1]\nobject Registry\n",
+			expected: []string{"A", "A.constructor()", "A.f(Int, vararg String)", "A.g((Int)->Unit)", "A.Companion", "A.Companion.make()", "List<T>.second()", "Registry"},
+		},
+		{
+			name:     "Строковые шаблоны и вложенные комментарии Kotlin",
+			lang:     langKotlin,
+			content:  "/* outer /* inner } */ fun hidden() {} */\nfun a() = \"${listOf(\"}\").first()}\"\nfun b() = \"\"\"{ $x \\\"\"\"\nfun c() {}\n",
+			expected: []string{"a()", "b()", "c()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			declarations, err := tt.lang.Extract(tt.content)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений: %v", err)
+			}
+			var keys []string
+			for _, decl := range declarations {
+				key := tt.lang.Key(decl)
+				if decl.Kind == DeclFunc {
+					key += "(" + decl.Signature + ")"
+				}
+				keys = append(keys, key)
+				if got := tt.content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+					t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+				}
+			}
+			if strings.Join(keys, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Ожидались ключи %v, получено %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_JavaOverloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		source   string
+		target   string
+		expected string
+	}{
+		{
+			name:     "Java",
+			lang:     langJava,
+			source:   "testdata/java_source.java",
+			target:   "testdata/java_target.java",
+			expected: "testdata/java_expected.java",
+		},
+		{
+			name:     "Изменённые параметры единственного метода",
+			lang:     langJava,
+			source:   "testdata/java_signature_source.java",
+			target:   "testdata/java_signature_target.java",
+			expected: "testdata/java_signature_expected.java",
+		},
+		{
+			name:     "Kotlin",
+			lang:     langKotlin,
+			source:   "testdata/kt_source.kt",
+			target:   "testdata/kt_target.kt",
+			expected: "testdata/kt_expected.kt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacer := NewFunctionReplacer()
+
+			sourceContent, err := readFile(tt.source)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.source, err)
+			}
+			targetContent, err := readFile(tt.target)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.target, err)
+			}
+			expected, err := readFile(tt.expected)
+			if err != nil {
+				t.Fatalf("Не удалось прочитать %s: %v", tt.expected, err)
+			}
+
+			sourceDeclarations, err := tt.lang.Extract(sourceContent)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+			}
+			result := replacer.replaceFunctions(targetContent, sourceDeclarations, tt.lang)
+			if result != expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+			}
+
+			if again := replacer.replaceFunctions(result, sourceDeclarations, tt.lang); again != result {
+				t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+			}
+		})
+	}
+}
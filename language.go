@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Language is a source language the replacer can sync declarations in.
+// FunctionReplacer only works through this interface, so a new language is
+// added by implementing it and listing it in languages.
+type Language interface {
+	// Name is the canonical --lang value of the language.
+	Name() string
+	// Family names the language in messages. Files of one family, such as
+	// .ts and .tsx, can be synced into each other.
+	Family() string
+	// Aliases are further --lang values that select the language.
+	Aliases() []string
+	// Extensions are the lower-case file extensions, with the dot, that
+	// select the language.
+	Extensions() []string
+	// ContentScore rates how much content looks like the language. It picks
+	// the language of a clipboard snippet.
+	ContentScore(content string) int
+	// Extract returns every entry of content that can be synced.
+	Extract(content string) ([]Function, error)
+	// Key returns the key that matches fn with its counterpart in the other
+	// file.
+	Key(fn Function) string
+	// Insert places new entries inside blocks the target already has, such
+	// as the class of a new method. It returns the edits and the entries that
+	// are still to be appended at the end of the target.
+	Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function)
+	// Finish post-processes the merged target, e.g. adds the imports the
+	// synced entries need and formats the result.
+	Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error)
+}
+
+// headerSyncer is implemented by languages whose definitions are declared
+// again in a paired file, such as C prototypes in a header. main updates that
+// file along with the target.
+type headerSyncer interface {
+	// PairedHeader returns the existing file that declares what path
+	// defines, or "" when there is none.
+	PairedHeader(path string) string
+	// SyncDeclarations updates the declarations in content to the signatures
+	// of definitions.
+	SyncDeclarations(content string, definitions []Function) string
+}
+
+// languages are the registered languages. On equal content scores the later
+// one wins, so the first one is the fallback when nothing matches.
+var languages = []Language{langGo, langTypeScript, langTSX, langJavaScript, langJSX, langPython, langRust, langJava, langCSharp, langKotlin, langC, langCPP}
+
+// detectLanguage returns the language of filename by its extension, or the
+// --lang override when it is set.
+func detectLanguage(filename, override string) (Language, error) {
+	if override != "" {
+		var names []string
+		for _, lang := range languages {
+			if strings.EqualFold(lang.Name(), override) {
+				return lang, nil
+			}
+			for _, alias := range lang.Aliases() {
+				if strings.EqualFold(alias, override) {
+					return lang, nil
+				}
+			}
+			names = append(names, lang.Name())
+		}
+		return nil, fmt.Errorf("неизвестный язык %q в --lang (поддерживаются %s)", override, strings.Join(names, ", "))
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, lang := range languages {
+		for _, langExt := range lang.Extensions() {
+			if ext == langExt {
+				return lang, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("неподдерживаемое расширение %q у файла %s: укажите язык через --lang", ext, filename)
+}
+
+// detectLanguageFromContent guesses the language of a snippet that comes
+// without a file name. Only its family is reliable: TypeScript and its JSX
+// variants look the same. The best score wins; a tie goes to the preferred
+// language, usually the target's, when the snippet looks like it at all, and
+// otherwise to the earlier language in languages.
+func detectLanguageFromContent(content string, preferred Language) Language {
+	best, bestScore := languages[0], languages[0].ContentScore(content)
+	for _, lang := range languages[1:] {
+		if score := lang.ContentScore(content); score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+	if preferred != nil && bestScore > 0 && preferred.ContentScore(content) == bestScore {
+		return preferred
+	}
+	return best
+}
+
+// countIndicators returns how many of the indicators occur in content,
+// ignoring case.
+func countIndicators(content string, indicators []string) int {
+	normalizedContent := strings.ToLower(content)
+	score := 0
+	for _, indicator := range indicators {
+		if strings.Contains(normalizedContent, indicator) {
+			score++
+		}
+	}
+	return score
+}
+
+// memberInsertion is the rendered text of the new members of a block, such
+// as a class, that the target already has.
+type memberInsertion struct {
+	block   Function // the block in the target
+	members []string // the rendered members, indented
+}
+
+// text joins the members with blank lines between them.
+func (ins memberInsertion) text() string {
+	return strings.Join(ins.members, "\n\n")
+}
+
+// blockMemberInsertions groups the new entries that belong to classes,
+// namespaces, object literals and impl blocks of the target by that block, in
+// source order, and drops the members of blocks that are added as a whole.
+// Blocks are matched by the qualified name their members use as Container.
+// Members are indented like the block's current members, or like the source
+// did relative to its block. A member whose block the target lacks is dropped
+// with a warning, as it is not valid at the top level. It returns the
+// insertions and the top-level entries that are still to be appended.
+func blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions []Function) ([]memberInsertion, []Function) {
+	blocksByKey := func(functions []Function) map[string]Function {
+		blocks := make(map[string]Function)
+		for _, fn := range functions {
+			if fn.Kind.isBlock() {
+				blocks[qualify(fn.Container, fn.Name)] = fn
+			}
+		}
+		return blocks
+	}
+	targetBlocks := blocksByKey(targetFunctions)
+	sourceBlocks := blocksByKey(sourceFunctions)
+	addedBlocks := blocksByKey(newFunctions)
+
+	insideAddedBlock := func(container string) bool {
+		for key := range addedBlocks {
+			if container == key || strings.HasPrefix(container, key+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var rest []Function
+	var order []string
+	members := make(map[string][]Function)
+	for _, fn := range newFunctions {
+		if fn.Container == "" {
+			rest = append(rest, fn)
+			continue
+		}
+		if insideAddedBlock(fn.Container) {
+			continue
+		}
+		if _, ok := targetBlocks[fn.Container]; !ok {
+			log.Printf("Предупреждение: %s не добавлен, в целевом файле нет блока %s", fn.Name, fn.Container)
+			continue
+		}
+		if len(members[fn.Container]) == 0 {
+			order = append(order, fn.Container)
+		}
+		members[fn.Container] = append(members[fn.Container], fn)
+	}
+
+	var insertions []memberInsertion
+	for _, container := range order {
+		block := targetBlocks[container]
+
+		// A member that shares its line with other code, such as one of a
+		// one-line object literal, does not tell the indentation
+		indent, found := "", false
+		for _, fn := range targetFunctions {
+			if fn.Container == container && leadingSpace(fn.Indent) == fn.Indent {
+				indent, found = fn.Indent, true
+				break
+			}
+		}
+		if !found {
+			first := members[container][0]
+			relative := strings.TrimPrefix(leadingSpace(first.Indent), leadingSpace(sourceBlocks[container].Indent))
+			if relative == "" {
+				relative = "    "
+			}
+			indent = block.Indent + relative
+		}
+
+		var texts []string
+		for _, fn := range members[container] {
+			doc, spec := declParts(fn)
+			texts = append(texts, indent+renderDecl(fn, doc, spec, Function{Indent: indent}))
+		}
+		insertions = append(insertions, memberInsertion{block: block, members: texts})
+	}
+	return insertions, rest
+}
+
+// leadingSpace returns the whitespace that starts s. The Indent of an entry
+// that shares its line with other code, such as a member of a one-line object
+// literal, also holds that code.
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// braceMemberEdits places each insertion before the closing brace of its
+// block, after the block's last member, or fills an empty "{}" block.
+func braceMemberEdits(targetContent string, insertions []memberInsertion) []textEdit {
+	var edits []textEdit
+	for _, ins := range insertions {
+		closeBrace := ins.block.EndPos - 1
+		last := len(strings.TrimRight(targetContent[:closeBrace], " \t\r\n"))
+		if targetContent[last-1] == '{' {
+			edits = append(edits, textEdit{start: last, end: closeBrace, text: "\n" + ins.text() + "\n" + ins.block.Indent})
+		} else {
+			edits = append(edits, textEdit{start: last, end: last, text: "\n\n" + ins.text()})
+		}
+	}
+	return edits
+}
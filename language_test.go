@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguageRegistry(t *testing.T) {
+	names := make(map[string]string)
+	extensions := make(map[string]string)
+	for _, lang := range languages {
+		for _, name := range append([]string{lang.Name()}, lang.Aliases()...) {
+			if other, ok := names[name]; ok {
+				t.Errorf("Значение --lang %q есть у языков %s и %s", name, other, lang.Name())
+			}
+			names[name] = lang.Name()
+		}
+		for _, ext := range lang.Extensions() {
+			if !strings.HasPrefix(ext, ".") || ext != strings.ToLower(ext) {
+				t.Errorf("Расширение %q языка %s должно начинаться с точки и быть в нижнем регистре", ext, lang.Name())
+			}
+			if other, ok := extensions[ext]; ok {
+				t.Errorf("Расширение %q есть у языков %s и %s", ext, other, lang.Name())
+			}
+			extensions[ext] = lang.Name()
+		}
+	}
+}
+
+func TestLanguageKeys(t *testing.T) {
+	tests := []struct {
+		lang     Language
+		fn       Function
+		expected string
+	}{
+		{lang: langGo, fn: Function{Name: "Serve", Receiver: "s *Service"}, expected: "Service.Serve"},
+		{lang: langGo, fn: Function{Name: "Push", Receiver: "l *List[T]"}, expected: "List.Push"},
+		{lang: langGo, fn: Function{Name: "Hello", Container: "ignored"}, expected: "Hello"},
+		{lang: langTypeScript, fn: Function{Name: "render", Container: "Header"}, expected: "Header.render"},
+		{lang: langTSX, fn: Function{Name: "list", Container: "api.users"}, expected: "api.users.list"},
+		{lang: langJavaScript, fn: Function{Name: "greet"}, expected: "greet"},
+		{lang: langRust, fn: Function{Name: "fmt", Container: "Display for Foo"}, expected: "Display for Foo.fmt"},
+		{lang: langRust, fn: Function{Name: "Foo", Kind: DeclImpl, Container: "shapes"}, expected: "impl shapes.Foo"},
+		{lang: langJava, fn: Function{Name: "save", Kind: DeclFunc, Container: "Repo", Signature: "List<User>"}, expected: "Repo.save"},
+		{lang: langKotlin, fn: Function{Name: "names", Kind: DeclFunc, Receiver: "List<User>", Signature: ""}, expected: "List<User>.names"},
+		{lang: langCSharp, fn: Function{Name: "Line", Kind: DeclClass, Container: "Shop"}, expected: "Shop.Line"},
+		{lang: langC, fn: Function{Name: "counter_add", Kind: DeclFunc}, expected: "counter_add"},
+		{lang: langCPP, fn: Function{Name: "Widget::resize", Kind: DeclFunc, Container: "ui", Signature: "int, int"}, expected: "ui::Widget::resize"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang.Name()+"/"+tt.expected, func(t *testing.T) {
+			if key := tt.lang.Key(tt.fn); key != tt.expected {
+				t.Errorf("Ожидался ключ %s, получен %s", tt.expected, key)
+			}
+		})
+	}
+}
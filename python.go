@@ -0,0 +1,268 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pyLineKind classifies a physical line of Python source.
+type pyLineKind int
+
+const (
+	pyBlank        pyLineKind = iota
+	pyComment                 // only a "#" comment
+	pyCode                    // starts a logical line
+	pyContinuation            // inside brackets or after a "\"
+	pyString                  // starts inside a multi-line string
+)
+
+// pyLine is one physical line of Python source.
+type pyLine struct {
+	start, end int // offsets of the line without its line break
+	indent     string
+	kind       pyLineKind
+}
+
+// splitPythonLines classifies the lines of content. Strings, including
+// triple-quoted ones, comments, brackets and backslash continuations are
+// taken into account, so that only lines that start a logical line are
+// pyCode.
+func splitPythonLines(content string) []pyLine {
+	var lines []pyLine
+	quote := ""     // delimiter of the string being scanned, if any
+	depth := 0      // bracket depth
+	joined := false // the previous line ended with a backslash
+	for pos := 0; pos < len(content); {
+		lineEnd := strings.IndexByte(content[pos:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += pos
+		}
+		text := content[pos:lineEnd]
+		body := strings.TrimLeft(text, " \t")
+		line := pyLine{start: pos, end: pos + len(strings.TrimRight(text, "\r")), indent: text[:len(text)-len(body)]}
+		switch {
+		case quote != "":
+			line.kind = pyString
+		case depth > 0 || joined:
+			line.kind = pyContinuation
+		case strings.TrimSpace(body) == "":
+			line.kind = pyBlank
+		case body[0] == '#':
+			line.kind = pyComment
+		default:
+			line.kind = pyCode
+		}
+		lines = append(lines, line)
+
+		joined = false
+		for i := pos; i < lineEnd; i++ {
+			c := content[i]
+			if quote != "" {
+				switch {
+				case c == '\\':
+					i++
+				case strings.HasPrefix(content[i:], quote):
+					i += len(quote) - 1
+					quote = ""
+				}
+				continue
+			}
+			switch c {
+			case '#':
+				i = lineEnd
+			case '\'', '"':
+				quote = string(c)
+				if strings.HasPrefix(content[i:], strings.Repeat(quote, 3)) {
+					quote = strings.Repeat(quote, 3)
+					i += 2
+				}
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				if depth > 0 {
+					depth--
+				}
+			case '\\':
+				joined = i+1 == lineEnd || i+2 == lineEnd && content[i+1] == '\r'
+			}
+		}
+		if len(quote) == 1 && !joined {
+			// An unterminated single-quoted string ends at the line break
+			quote = ""
+		}
+		pos = lineEnd + 1
+	}
+	return lines
+}
+
+var (
+	pyDefRegex   = regexp.MustCompile(`^(?:async\s+)?def\s+([\p{L}_][\p{L}\p{N}_]*)\s*[\[(]`)
+	pyClassRegex = regexp.MustCompile(`^class\s+([\p{L}_][\p{L}\p{N}_]*)\s*[\[(:]`)
+)
+
+// pyParser collects the functions, classes and methods of a Python source.
+type pyParser struct {
+	content string
+	lines   []pyLine
+	decls   []Function
+}
+
+// extractPythonDeclarations returns the module-level functions and classes
+// of content and the methods of those classes, nested classes included.
+// Functions nested in functions are part of their enclosing function.
+func extractPythonDeclarations(content string) []Function {
+	p := &pyParser{content: content, lines: splitPythonLines(content)}
+	p.parseBlock(0, len(p.lines), "")
+	return p.decls
+}
+
+// parseBlock walks the statements in lines[from:to], which all share the
+// indentation of the first code line.
+func (p *pyParser) parseBlock(from, to int, container string) {
+	indent := -1
+	decorated := -1
+	for i := from; i < to; i++ {
+		line := p.lines[i]
+		if line.kind != pyCode {
+			continue
+		}
+		if indent == -1 {
+			indent = len(line.indent)
+		}
+		if len(line.indent) != indent {
+			continue
+		}
+
+		first := i
+		if decorated != -1 {
+			first = decorated
+		}
+		text := p.content[line.start+len(line.indent) : line.end]
+		if strings.HasPrefix(text, "@") {
+			if decorated == -1 {
+				decorated = i
+			}
+			continue
+		}
+		decorated = -1
+
+		if m := pyDefRegex.FindStringSubmatch(text); m != nil {
+			end := p.blockEnd(i)
+			p.addDecl(m[1], container, DeclFunc, first, end-1)
+			i = end - 1
+		} else if m := pyClassRegex.FindStringSubmatch(text); m != nil {
+			end := p.blockEnd(i)
+			p.addDecl(m[1], container, DeclClass, first, end-1)
+			p.parseBlock(i+1, end, qualify(container, m[1]))
+			i = end - 1
+		}
+	}
+}
+
+// blockEnd returns the index just past the last line of the compound
+// statement whose header starts at line i: the header's continuation lines
+// and the body lines indented deeper than the header. Trailing blank and
+// comment lines are left out.
+func (p *pyParser) blockEnd(i int) int {
+	end := i + 1
+	for end < len(p.lines) && (p.lines[end].kind == pyContinuation || p.lines[end].kind == pyString) {
+		end++
+	}
+	indent := len(p.lines[i].indent)
+	for k := end; k < len(p.lines); k++ {
+		line := p.lines[k]
+		if line.kind == pyCode && len(line.indent) <= indent {
+			break
+		}
+		if line.kind != pyBlank && line.kind != pyComment {
+			end = k + 1
+		}
+	}
+	return end
+}
+
+// addDecl records the declaration spanning lines[first:last+1] together with
+// the "#" comment lines directly above it. Lines inside multi-line strings are
+// marked so that re-indenting leaves the strings as they are.
+func (p *pyParser) addDecl(name, container string, kind DeclKind, first, last int) {
+	head := p.lines[first]
+	pos := head.start + len(head.indent)
+	k := first
+	for k > 0 && p.lines[k-1].kind == pyComment && p.lines[k-1].indent == head.indent {
+		k--
+	}
+	start := p.lines[k].start + len(head.indent)
+	end := p.lines[last].end
+	var literal []int
+	for j := first; j <= last; j++ {
+		if p.lines[j].kind == pyString {
+			literal = append(literal, j-first)
+		}
+	}
+	p.decls = append(p.decls, Function{
+		Name:      name,
+		Kind:      kind,
+		Container: container,
+		Doc:       p.content[start:pos],
+		FullText:  p.content[start:end],
+		StartPos:  start,
+		EndPos:    end,
+		Indent:    head.indent,
+		Literal:   literal,
+	})
+}
+
+// pythonLanguage syncs Python sources: functions and classes keyed like
+// "Class.method".
+type pythonLanguage struct{}
+
+var langPython = pythonLanguage{}
+
+func (pythonLanguage) Name() string         { return "python" }
+func (pythonLanguage) Family() string       { return "Python" }
+func (pythonLanguage) Aliases() []string    { return []string{"py"} }
+func (pythonLanguage) Extensions() []string { return []string{".py", ".pyi"} }
+
+// pyIndicators are the snippets that make content look like Python.
+var pyIndicators = []string{"def ", "self)", "self,", "self.", "elif ", "__init__", "__name__", "):\n", "lambda "}
+
+func (pythonLanguage) ContentScore(content string) int {
+	return countIndicators(content, pyIndicators)
+}
+
+func (pythonLanguage) Extract(content string) ([]Function, error) {
+	return extractPythonDeclarations(content), nil
+}
+
+// Key qualifies methods with their class, e.g. "Class.method".
+func (pythonLanguage) Key(fn Function) string {
+	return qualify(fn.Container, fn.Name)
+}
+
+// Insert places new methods after the last line of their class, re-indented
+// to the class's members, and appends new module-level entries separated by
+// two blank lines as PEP 8 asks.
+func (pythonLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	insertions, rest := blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions)
+
+	var edits []textEdit
+	for _, ins := range insertions {
+		edits = append(edits, textEdit{start: ins.block.EndPos, end: ins.block.EndPos, text: "\n\n" + ins.text()})
+	}
+	if strings.TrimSpace(targetContent) == "" {
+		return edits, rest
+	}
+
+	last := len(strings.TrimRight(targetContent, " \t\r\n"))
+	for _, fn := range rest {
+		doc, spec := declParts(fn)
+		edits = append(edits, textEdit{start: last, end: last, text: "\n\n\n" + renderDecl(fn, doc, spec, Function{})})
+	}
+	return edits, nil
+}
+
+func (pythonLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	return content, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractPythonDeclarations(t *testing.T) {
+	content, err := readFile("testdata/py_source.py")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractPythonDeclarations(content)
+
+	var keys []string
+	for _, decl := range declarations {
+		keys = append(keys, langPython.Key(decl))
+		if got := content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+			t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+		}
+	}
+	expected := []string{"normalize", "Model", "Model.predict", "Model.warmup", "Model.describe", "Model.Config", "Model.Config.defaults", "train", "Scheduler", "Scheduler.step"}
+	if strings.Join(keys, " ") != strings.Join(expected, " ") {
+		t.Fatalf("Ожидались ключи %v, получено %v", expected, keys)
+	}
+
+	if doc := declarations[0].Doc; doc != "# Normalizes a batch to zero mean.\n" {
+		t.Errorf("Неверный комментарий функции normalize: %q", doc)
+	}
+	if predict := declarations[2].FullText; !strings.HasPrefix(predict, "@torch.no_grad()\n  def predict") {
+		t.Errorf("Декоратор не вошёл в метод predict:\n%s", predict)
+	}
+	if describe := declarations[4].FullText; !strings.HasSuffix(describe, "    pass\n\"\"\"") {
+		t.Errorf("Метод describe обрезан на строке в тройных кавычках:\n%s", describe)
+	}
+}
+
+func TestSplitPythonLines(t *testing.T) {
+	content := "x = (1,\n  2)\ns = '''\n# text\n'''\ny = 1 + \\\n  2\n\n  # note\nz = '#' # ( comment\nw = 1\n"
+
+	var kinds []pyLineKind
+	for _, line := range splitPythonLines(content) {
+		kinds = append(kinds, line.kind)
+	}
+	expected := []pyLineKind{pyCode, pyContinuation, pyCode, pyString, pyString, pyCode, pyContinuation, pyBlank, pyComment, pyCode, pyCode}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Ожидалось %d строк, получено %d: %v", len(expected), len(kinds), kinds)
+	}
+	for i := range kinds {
+		if kinds[i] != expected[i] {
+			t.Errorf("Строка %d: ожидался вид %d, получен %d", i, expected[i], kinds[i])
+		}
+	}
+}
+
+func TestReplaceFunctions_Python(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/py_source.py")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать py_source.py: %v", err)
+	}
+	targetContent, err := readFile("testdata/py_target.py")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать py_target.py: %v", err)
+	}
+	expected, err := readFile("testdata/py_expected.py")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать py_expected.py: %v", err)
+	}
+
+	sourceDeclarations, err := langPython.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langPython)
+	if result != expected {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+	}
+
+	if again := replacer.replaceFunctions(result, sourceDeclarations, langPython); again != result {
+		t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+	}
+}
@@ -6,70 +6,69 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/atotto/clipboard"
 )
 
+// DeclKind tells what kind of declaration a Function entry holds.
+type DeclKind string
+
+const (
+	DeclFunc  DeclKind = "func"
+	DeclType  DeclKind = "type"
+	DeclVar   DeclKind = "var"
+	DeclConst DeclKind = "const"
+
+	// TypeScript-only kinds; type aliases use DeclType
+	DeclInterface DeclKind = "interface"
+	DeclEnum      DeclKind = "enum"
+	DeclClass     DeclKind = "class"
+	DeclNamespace DeclKind = "namespace"
+	DeclObject    DeclKind = "object" // an object literal whose methods are members
+
+	// Rust impl blocks; structs and enums use DeclType, traits DeclClass
+	DeclImpl DeclKind = "impl"
+)
+
+// isBlock tells whether entries of the kind hold members that are synced one
+// by one rather than replaced as a whole.
+func (k DeclKind) isBlock() bool {
+	return k == DeclClass || k == DeclNamespace || k == DeclImpl || k == DeclObject
+}
+
 type Function struct {
-	Name     string
-	Receiver string // Go-specific
-	FullText string
-	StartPos int // For sorting and potentially more robust deduplication
+	Name      string
+	Kind      DeclKind
+	Receiver  string // Go method receiver or Kotlin extension receiver type
+	Container string // Dot-separated enclosing namespaces, classes, object literals and impl blocks
+	Signature string // Parameter types of a method in a language with overloading
+	Doc       string // Leading doc comment and directives; FullText starts with it
+	FullText  string
+	StartPos  int    // For sorting and potentially more robust deduplication
+	EndPos    int    // Byte offset just past FullText in the content it was extracted from
+	Group     string // Go-specific: identifies the grouped "var (...)" block of a spec
+	Indent    string // Indentation of the first line of a grouped spec or a class member
+	Literal   []int  // Lines after Doc, counted from 0, inside a multi-line string; they are never re-indented
 }
 
 type FunctionReplacer struct {
+	// KeepTargetDoc keeps the doc comment of a replaced function as it is in
+	// the target instead of taking the one from the source.
+	KeepTargetDoc bool
+	// PruneImports removes Go imports that are no longer used after merging.
+	PruneImports bool
+	// NoFormat leaves the merged Go source unformatted; its syntax is still
+	// checked.
+	NoFormat bool
 }
 
 func NewFunctionReplacer() *FunctionReplacer {
 	return &FunctionReplacer{}
 }
 
-// Helper to check if a function match is commented out
-func isMatchCommented(content string, matchStartIndex int) bool {
-	// Check for single-line comment on the same line before the match
-	lineStart := 0
-	if idx := strings.LastIndex(content[:matchStartIndex], "\n"); idx != -1 {
-		lineStart = idx + 1
-	}
-
-	lineContentBeforeMatch := content[lineStart:matchStartIndex]
-	trimmedLineContentBeforeMatch := strings.TrimSpace(lineContentBeforeMatch)
-
-	if strings.HasPrefix(trimmedLineContentBeforeMatch, "//") {
-		return true
-	}
-
-	// Check for block comment /* ... */ by scanning content before the match
-	openComment := "/*"
-	closeComment := "*/"
-	inBlockComment := false
-	searchArea := content[:matchStartIndex]
-
-	idx := 0
-	for idx < len(searchArea) {
-		isOpen := false
-		isClose := false
-		if !inBlockComment && idx+len(openComment) <= len(searchArea) && searchArea[idx:idx+len(openComment)] == openComment {
-			isOpen = true
-		} else if inBlockComment && idx+len(closeComment) <= len(searchArea) && searchArea[idx:idx+len(closeComment)] == closeComment {
-			isClose = true
-		}
-
-		if isOpen {
-			inBlockComment = true
-			idx += len(openComment)
-		} else if isClose {
-			inBlockComment = false
-			idx += len(closeComment)
-		} else {
-			idx++
-		}
-	}
-	return inBlockComment
-}
-
 // min helper for logging snippets
 func min(a, b int) int {
 	if a < b {
@@ -78,226 +77,190 @@ func min(a, b int) int {
 	return b
 }
 
-// extractGoFunctionWithBraceBalancing extracts a Go function using brace balancing approach
+// extractGoFunctionWithBraceBalancing extracts a Go function using brace balancing approach.
+// Strings, runes, raw strings and comments are skipped with skipGoLiteral, and
+// braces of struct{...}/interface{...} types in the signature are not taken
+// for the body.
 func extractGoFunctionWithBraceBalancing(content string, startIndex int) (string, int, error) {
-	// Find the opening brace
+	// Find the opening brace of the body
 	braceIndex := -1
-	for i := startIndex; i < len(content); i++ {
-		if content[i] == '{' {
-			braceIndex = i
-			break
+	parenDepth, bracketDepth := 0, 0
+	lastWord := ""
+	for i := startIndex; i < len(content) && braceIndex == -1; {
+		if next := skipGoLiteral(content, i); next > i {
+			i = next
+			continue
 		}
-		// If we encounter a newline without finding an opening brace and there's
-		// no "=" or other continuation, this might not be a function definition
-		if content[i] == '\n' {
+		c := content[i]
+		switch {
+		case c == '(':
+			parenDepth++
+		case c == ')':
+			parenDepth--
+		case c == '[':
+			bracketDepth++
+		case c == ']':
+			bracketDepth--
+		case c == '{':
+			if parenDepth == 0 && bracketDepth == 0 && lastWord != "struct" && lastWord != "interface" {
+				braceIndex = i
+				continue
+			}
+			// A type literal in the signature: skip it as a whole
+			end, err := balanceGoBraces(content, i+1)
+			if err != nil {
+				return "", -1, err
+			}
+			i = end
+			lastWord = ""
+			continue
+		case c == '\n' && parenDepth == 0 && bracketDepth == 0:
+			// If we encounter a newline without finding an opening brace and there's
+			// no "=" or other continuation, this might not be a function definition
 			line := strings.TrimSpace(content[startIndex:i])
 			if !strings.Contains(line, "=") && !strings.HasSuffix(line, ",") && !strings.HasSuffix(line, "(") {
 				return "", -1, fmt.Errorf("no opening brace found")
 			}
+		case isGoIdentChar(c):
+			wordStart := i
+			for i < len(content) && isGoIdentChar(content[i]) {
+				i++
+			}
+			lastWord = content[wordStart:i]
+			continue
 		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			lastWord = ""
+		}
+		i++
 	}
 
 	if braceIndex == -1 {
 		return "", -1, fmt.Errorf("no opening brace found")
 	}
 
-	// Now balance braces
+	endIndex, err := balanceGoBraces(content, braceIndex+1)
+	if err != nil {
+		return "", -1, err
+	}
+	return content[startIndex:endIndex], endIndex, nil
+}
+
+// balanceGoBraces scans from just after an opening brace and returns the index
+// just past its matching closing brace.
+func balanceGoBraces(content string, startIndex int) (int, error) {
 	braceCount := 1
-	endIndex := braceIndex + 1
+	endIndex := startIndex
 
 	for endIndex < len(content) && braceCount > 0 {
+		if next := skipGoLiteral(content, endIndex); next > endIndex {
+			endIndex = next
+			continue
+		}
 		switch content[endIndex] {
 		case '{':
 			braceCount++
 		case '}':
 			braceCount--
-		case '"':
-			// Skip string literals
-			endIndex++
-			for endIndex < len(content) && content[endIndex] != '"' {
-				if content[endIndex] == '\\' {
-					endIndex++ // Skip escaped character
-				}
-				endIndex++
-			}
-		case '\'':
-			// Skip character literals
-			endIndex++
-			for endIndex < len(content) && content[endIndex] != '\'' {
-				if content[endIndex] == '\\' {
-					endIndex++ // Skip escaped character
-				}
-				endIndex++
-			}
-		case '/':
-			// Skip comments
-			if endIndex+1 < len(content) {
-				if content[endIndex+1] == '/' {
-					// Single-line comment
-					for endIndex < len(content) && content[endIndex] != '\n' {
-						endIndex++
-					}
-					continue
-				} else if content[endIndex+1] == '*' {
-					// Multi-line comment
-					endIndex += 2
-					for endIndex+1 < len(content) {
-						if content[endIndex] == '*' && content[endIndex+1] == '/' {
-							endIndex += 2
-							break
-						}
-						endIndex++
-					}
-					continue
-				}
-			}
 		}
 		endIndex++
 	}
 
 	if braceCount != 0 {
-		return "", -1, fmt.Errorf("unbalanced braces")
+		return -1, fmt.Errorf("unbalanced braces")
 	}
-
-	return content[startIndex:endIndex], endIndex, nil
+	return endIndex, nil
 }
 
-func (fr *FunctionReplacer) extractFunctions(content string, isGoFile bool) ([]Function, error) {
+// extractGoFunctionsLightweight is the regex and brace balancing fallback used
+// for Go snippets that go/parser rejects.
+func extractGoFunctionsLightweight(content string) []Function {
 	var functions []Function
 
-	if isGoFile {
-		isPotentiallyProblematic := strings.Contains(content, "RequestPremiumSession")
-
-		if isPotentiallyProblematic {
-			log.Printf("[EXTRACT_GO_DEBUG] Processing Go content containing 'RequestPremiumSession'. Content length: %d", len(content))
-			// Выводим первые N символов для проверки на мусор в начале строки
-			log.Printf("[EXTRACT_GO_DEBUG] Content Snippet (first 500 chars):\n<<<<SNIPPET_START>>>>\n%s\n<<<<SNIPPET_END>>>>", content[:min(500, len(content))])
-		}
+	isPotentiallyProblematic := strings.Contains(content, "RequestPremiumSession")
 
-		// Use a simpler regex to find function headers, then use brace balancing for body
-		funcHeaderRegexStr := `func\s*(?:\([^)]*\)\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*\(`
-		funcHeaderRegex := regexp.MustCompile(funcHeaderRegexStr)
-
-		if isPotentiallyProblematic {
-			log.Printf("[EXTRACT_GO_DEBUG] Using Header Regex: %s", funcHeaderRegexStr)
-		}
-
-		matchesIndices := funcHeaderRegex.FindAllStringSubmatchIndex(content, -1)
+	if isPotentiallyProblematic {
+		log.Printf("[EXTRACT_GO_DEBUG] Processing Go content containing 'RequestPremiumSession'. Content length: %d", len(content))
+		// Выводим первые N символов для проверки на мусор в начале строки
+		log.Printf("[EXTRACT_GO_DEBUG] Content Snippet (first 500 chars):\n<<<<SNIPPET_START>>>>\n%s\n<<<<SNIPPET_END>>>>", content[:min(500, len(content))])
+	}
 
-		if isPotentiallyProblematic {
-			log.Printf("[EXTRACT_GO_DEBUG] Found %d potential header matches with this regex.", len(matchesIndices))
-		}
+	// Use a simpler regex to find function headers, then use brace balancing for body.
+	// The name may be followed by a type parameter list: func Map[T, U any](...)
+	funcHeaderRegexStr := `func\s*(?:\([^)]*\)\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*[\[(]`
+	funcHeaderRegex := regexp.MustCompile(funcHeaderRegexStr)
 
-		for i, matchIdx := range matchesIndices {
-			if len(matchIdx) < 4 {
-				if isPotentiallyProblematic {
-					log.Printf("[EXTRACT_GO_DEBUG] Match %d has insufficient indices: %v. Skipping.", i, matchIdx)
-				}
-				continue
-			}
+	if isPotentiallyProblematic {
+		log.Printf("[EXTRACT_GO_DEBUG] Using Header Regex: %s", funcHeaderRegexStr)
+	}
 
-			funcName := content[matchIdx[2]:matchIdx[3]]
-			matchStartIndexInContent := matchIdx[0]
+	matchesIndices := funcHeaderRegex.FindAllStringSubmatchIndex(content, -1)
+	literalRanges := goLiteralRanges(content)
+	lastEndIndex := 0
 
-			if isPotentiallyProblematic {
-				log.Printf("[EXTRACT_GO_DEBUG] Potential Match %d: Name='%s', StartIndex=%d", i, funcName, matchStartIndexInContent)
-			}
+	if isPotentiallyProblematic {
+		log.Printf("[EXTRACT_GO_DEBUG] Found %d potential header matches with this regex.", len(matchesIndices))
+	}
 
-			commented := isMatchCommented(content, matchStartIndexInContent)
+	for i, matchIdx := range matchesIndices {
+		if len(matchIdx) < 4 {
 			if isPotentiallyProblematic {
-				log.Printf("[EXTRACT_GO_DEBUG] Potential Match %d: Name='%s', IsCommented: %v", i, funcName, commented)
+				log.Printf("[EXTRACT_GO_DEBUG] Match %d has insufficient indices: %v. Skipping.", i, matchIdx)
 			}
+			continue
+		}
 
-			if commented {
-				continue
-			}
+		funcName := content[matchIdx[2]:matchIdx[3]]
+		matchStartIndexInContent := matchIdx[0]
 
-			// Extract full function using brace balancing
-			fullFunctionText, endIndex, err := extractGoFunctionWithBraceBalancing(content, matchStartIndexInContent)
-			if err != nil {
-				if isPotentiallyProblematic {
-					log.Printf("[EXTRACT_GO_DEBUG] Failed to extract function %s: %v", funcName, err)
-				}
-				continue
-			}
+		if isPotentiallyProblematic {
+			log.Printf("[EXTRACT_GO_DEBUG] Potential Match %d: Name='%s', StartIndex=%d", i, funcName, matchStartIndexInContent)
+		}
 
-			receiver := extractGoReceiver(fullFunctionText)
-			if isPotentiallyProblematic {
-				log.Printf("[EXTRACT_GO_DEBUG] Match %d: Name='%s', Receiver: '%s', EndIndex: %d. Adding to results.", i, funcName, receiver, endIndex)
-			}
+		commented := insideRanges(literalRanges, matchStartIndexInContent) || matchStartIndexInContent < lastEndIndex
+		if isPotentiallyProblematic {
+			log.Printf("[EXTRACT_GO_DEBUG] Potential Match %d: Name='%s', IsCommented: %v", i, funcName, commented)
+		}
 
-			functions = append(functions, Function{
-				Name:     funcName,
-				Receiver: receiver,
-				FullText: strings.TrimSpace(fullFunctionText),
-				StartPos: matchStartIndexInContent,
-			})
+		if commented {
+			continue
 		}
-		if isPotentiallyProblematic {
-			log.Printf("[EXTRACT_GO_DEBUG] Finished processing. Extracted %d functions for this content.", len(functions))
-			for idx, f := range functions {
-				log.Printf("[EXTRACT_GO_DEBUG] Result %d: Name: %s", idx, f.Name)
+
+		// Extract full function using brace balancing
+		fullFunctionText, endIndex, err := extractGoFunctionWithBraceBalancing(content, matchStartIndexInContent)
+		if err != nil {
+			if isPotentiallyProblematic {
+				log.Printf("[EXTRACT_GO_DEBUG] Failed to extract function %s: %v", funcName, err)
 			}
+			continue
 		}
 
-	} else { // TypeScript
-		tempFunctions := []Function{}
-
-		regexes := []struct {
-			regex    *regexp.Regexp
-			isMethod bool
-		}{
-			{
-				regex:    regexp.MustCompile(`(?s)((?:export\s+)?(?:async\s+)?function\s+([A-Za-z_][A-Za-z0-9_]*)(?:\s*<[^>]+>)?\s*\((?:[^)]|\n)*\)\s*(?::\s*[^\{]+)?{((?:[^{}]|{[^{}]*})*)})`),
-				isMethod: false,
-			},
-			{
-				regex:    regexp.MustCompile(`(?s)((?:export\s+)?(?:const|let|var)\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(?:async\s+)?(?:<[^>]+>)?\s*\((?:[^)]|\n)*\)\s*(?::\s*[^=]+)?\s*=>\s*{((?:[^{}]|{[^{}]*})*)})`),
-				isMethod: false,
-			},
-			{
-				regex:    regexp.MustCompile(`(?m)^([ \t]*(?:(?:public|private|protected|static|async)\s+)?([A-Za-z_][A-Za-z0-9_]*)(?:\s*<[^>]+>)?\s*\((?:[^)]|\n)*\)\s*(?::\s*[^\{]+)?{((?:[^{}]|{[^{}]*})*)})`),
-				isMethod: true,
-			},
-		}
-
-		for _, r := range regexes {
-			matches := r.regex.FindAllStringSubmatchIndex(content, -1)
-			for _, matchIdx := range matches {
-				fullMatchText := content[matchIdx[0]:matchIdx[1]]
-				funcName := content[matchIdx[4]:matchIdx[5]]
-				matchStartIndex := matchIdx[0]
-
-				if isMatchCommented(content, matchStartIndex) {
-					continue
-				}
-
-				if r.isMethod && funcName == "constructor" {
-					continue
-				}
+		lastEndIndex = endIndex
 
-				tempFunctions = append(tempFunctions, Function{
-					Name:     funcName,
-					FullText: strings.TrimSpace(fullMatchText),
-					StartPos: matchStartIndex,
-				})
-			}
+		receiver := extractGoReceiver(fullFunctionText)
+		docStart := goLeadingCommentStart(content, matchStartIndexInContent)
+		if isPotentiallyProblematic {
+			log.Printf("[EXTRACT_GO_DEBUG] Match %d: Name='%s', Receiver: '%s', EndIndex: %d. Adding to results.", i, funcName, receiver, endIndex)
 		}
 
-		sort.SliceStable(tempFunctions, func(i, j int) bool {
-			return tempFunctions[i].StartPos < tempFunctions[j].StartPos
+		functions = append(functions, Function{
+			Name:     funcName,
+			Kind:     DeclFunc,
+			Receiver: receiver,
+			Doc:      content[docStart:matchStartIndexInContent],
+			FullText: content[docStart:matchStartIndexInContent] + strings.TrimSpace(fullFunctionText),
+			StartPos: docStart,
+			EndPos:   endIndex,
 		})
-
-		seenStartPos := make(map[int]bool)
-		for _, fn := range tempFunctions {
-			if !seenStartPos[fn.StartPos] {
-				functions = append(functions, fn)
-				seenStartPos[fn.StartPos] = true
-			}
+	}
+	if isPotentiallyProblematic {
+		log.Printf("[EXTRACT_GO_DEBUG] Finished processing. Extracted %d functions for this content.", len(functions))
+		for idx, f := range functions {
+			log.Printf("[EXTRACT_GO_DEBUG] Result %d: Name: %s", idx, f.Name)
 		}
 	}
 
-	return functions, nil
+	return functions
 }
 
 func extractGoReceiver(funcText string) string {
@@ -311,33 +274,65 @@ func extractGoReceiver(funcText string) string {
 	return ""
 }
 
-func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctions []Function, isGoFile bool) string {
-	result := targetContent
-
-	targetFunctions, err := fr.extractFunctions(targetContent, isGoFile)
+func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctions []Function, lang Language) string {
+	targetFunctions, err := lang.Extract(targetContent)
 	if err != nil {
 		log.Printf("Предупреждение: ошибка при парсинге целевого файла для существующих функций: %v", err)
 	}
 
+	keyOf := matchKeys(lang, targetFunctions, sourceFunctions)
 	targetFuncMap := make(map[string]Function)
 	for _, fn := range targetFunctions {
-		key := fr.getFunctionKey(fn, isGoFile)
+		key := keyOf(fn)
 		targetFuncMap[key] = fn
 	}
 
+	// A new spec of a grouped source block goes into the target block that
+	// already holds one of its siblings, after that block's last spec
+	targetGroupOf := make(map[string]string)
+	for _, sourceFn := range sourceFunctions {
+		if sourceFn.Group == "" {
+			continue
+		}
+		if targetFn, exists := targetFuncMap[keyOf(sourceFn)]; exists && targetFn.Group != "" {
+			if _, seen := targetGroupOf[sourceFn.Group]; !seen {
+				targetGroupOf[sourceFn.Group] = targetFn.Group
+			}
+		}
+	}
+	lastSpecOfGroup := make(map[string]Function)
+	for _, fn := range targetFunctions {
+		if fn.Group != "" && fn.EndPos > lastSpecOfGroup[fn.Group].EndPos {
+			lastSpecOfGroup[fn.Group] = fn
+		}
+	}
+
 	processedTargetKeys := make(map[string]bool)
+	var edits []textEdit
 	var newFunctionsToAdd []Function
 
 	for _, sourceFn := range sourceFunctions {
-		key := fr.getFunctionKey(sourceFn, isGoFile)
+		key := keyOf(sourceFn)
+		if targetGroup, ok := targetGroupOf[sourceFn.Group]; ok && !processedTargetKeys[key] {
+			if _, exists := targetFuncMap[key]; !exists {
+				lastSpec := lastSpecOfGroup[targetGroup]
+				doc, spec := declParts(sourceFn)
+				edits = append(edits, textEdit{start: lastSpec.EndPos, end: lastSpec.EndPos, text: "\n" + lastSpec.Indent + renderDecl(sourceFn, doc, spec, lastSpec)})
+				processedTargetKeys[key] = true
+				continue
+			}
+		}
 		if targetFn, exists := targetFuncMap[key]; exists {
+			if sourceFn.Kind.isBlock() {
+				// The members of a class, namespace, object literal or impl
+				// block present in both are synced one by one
+				continue
+			}
 			if !processedTargetKeys[key] {
-				if strings.TrimSpace(targetFn.FullText) != "" && strings.Contains(result, targetFn.FullText) {
-					result = strings.Replace(result, targetFn.FullText, sourceFn.FullText, 1)
-				} else if strings.TrimSpace(targetFn.FullText) == "" {
-                    log.Printf("Warning: Key '%s' matched for source func '%s', but target FullText was empty. Skipping replacement. Source FullText: `%s`", key, sourceFn.Name, sourceFn.FullText)
-                } else {
-					log.Printf("Warning: Key '%s' matched for source func '%s', but target FullText (`%s`) was not found in current result for replacement. Target might have been modified or FullText extraction differs. Source FullText: `%s`", key, sourceFn.Name, targetFn.FullText, sourceFn.FullText)
+				if targetFn.StartPos < targetFn.EndPos && targetFn.EndPos <= len(targetContent) && targetContent[targetFn.StartPos:targetFn.EndPos] == targetFn.FullText {
+					edits = append(edits, textEdit{start: targetFn.StartPos, end: targetFn.EndPos, text: fr.replacementText(targetFn, sourceFn)})
+				} else {
+					log.Printf("Warning: Key '%s' matched for source func '%s', but target range %d-%d does not hold its FullText (`%s`). Skipping replacement.", key, sourceFn.Name, targetFn.StartPos, targetFn.EndPos, targetFn.FullText)
 				}
 				processedTargetKeys[key] = true
 			}
@@ -346,6 +341,12 @@ func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctio
 		}
 	}
 
+	insertEdits, rest := lang.Insert(targetContent, targetFunctions, sourceFunctions, newFunctionsToAdd)
+	edits = append(edits, insertEdits...)
+	newFunctionsToAdd = rest
+
+	result := applyEdits(targetContent, edits)
+
 	if len(newFunctionsToAdd) > 0 {
 		sb := strings.Builder{}
 		sb.WriteString(result)
@@ -354,7 +355,7 @@ func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctio
 			sb.WriteString("\n")
 		}
 
-		for i, sourceFnToAdd := range newFunctionsToAdd {
+		for i, text := range fr.additionTexts(newFunctionsToAdd) {
 			currentResultString := sb.String()
 			if len(strings.TrimSpace(currentResultString)) > 0 {
 				if !strings.HasSuffix(currentResultString, "\n\n") && !strings.HasSuffix(currentResultString, "\n\n\n") {
@@ -365,11 +366,10 @@ func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctio
 					}
 				}
 			} else if i > 0 {
-                 sb.WriteString("\n\n")
-            }
-
+				sb.WriteString("\n\n")
+			}
 
-			sb.WriteString(sourceFnToAdd.FullText)
+			sb.WriteString(text)
 			sb.WriteString("\n")
 		}
 		result = sb.String()
@@ -377,20 +377,175 @@ func (fr *FunctionReplacer) replaceFunctions(targetContent string, sourceFunctio
 	return result
 }
 
-func (fr *FunctionReplacer) getFunctionKey(fn Function, isGoFile bool) string {
-	if isGoFile && fn.Receiver != "" {
-		receiverParts := strings.Fields(fn.Receiver)
-		var receiverType string
-		if len(receiverParts) > 0 {
-			receiverType = strings.TrimPrefix(receiverParts[len(receiverParts)-1], "*")
+// matchKeys returns the key that pairs source and target declarations. A
+// function whose key is shared by another function of the target or of the
+// source is an overload and gets its parameter types added, so that overloads
+// are matched one by one while a lone method whose parameters changed still
+// replaces its old version.
+func matchKeys(lang Language, targetFunctions, sourceFunctions []Function) func(Function) string {
+	overloaded := make(map[string]bool)
+	for _, functions := range [][]Function{targetFunctions, sourceFunctions} {
+		seen := make(map[string]bool)
+		for _, fn := range functions {
+			if fn.Kind != DeclFunc {
+				continue
+			}
+			key := lang.Key(fn)
+			if seen[key] {
+				overloaded[key] = true
+			}
+			seen[key] = true
+		}
+	}
+	return func(fn Function) string {
+		key := lang.Key(fn)
+		if fn.Kind == DeclFunc && overloaded[key] {
+			key += "(" + fn.Signature + ")"
+		}
+		return key
+	}
+}
+
+// additionTexts renders the entries appended at the end of the target. New
+// specs that came from the same grouped source block are kept together in a
+// new grouped block, so that iota and implicit repetition keep working.
+func (fr *FunctionReplacer) additionTexts(functions []Function) []string {
+	groupSizes := make(map[string]int)
+	for _, fn := range functions {
+		if fn.Group != "" {
+			groupSizes[fn.Group]++
 		}
-		if receiverType != "" {
-			return fmt.Sprintf("%s.%s", receiverType, fn.Name)
+	}
+
+	var texts []string
+	rendered := make(map[string]bool)
+	for _, fn := range functions {
+		if fn.Group == "" || groupSizes[fn.Group] == 1 {
+			doc, spec := declParts(fn)
+			texts = append(texts, renderDecl(fn, doc, spec, Function{}))
+			continue
+		}
+		if rendered[fn.Group] {
+			continue
 		}
-		return fmt.Sprintf("receiver_%s.%s", strings.ReplaceAll(fn.Receiver, " ", "_"), fn.Name)
+		rendered[fn.Group] = true
+
+		like := Function{Group: fn.Group, Indent: "\t"}
+		var sb strings.Builder
+		sb.WriteString(string(fn.Kind) + " (\n")
+		for _, member := range functions {
+			if member.Group == fn.Group {
+				doc, spec := declParts(member)
+				sb.WriteString("\t" + renderDecl(member, doc, spec, like) + "\n")
+			}
+		}
+		sb.WriteString(")")
+		texts = append(texts, sb.String())
+	}
+	return texts
+}
+
+// textEdit replaces content[start:end] with text.
+type textEdit struct {
+	start, end int
+	text       string
+}
 
+// applyEdits applies non-overlapping edits to content. An edit that overlaps
+// an earlier one is skipped with a warning.
+func applyEdits(content string, edits []textEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, edit := range edits {
+		if edit.start < last {
+			log.Printf("Предупреждение: пропущена пересекающаяся замена в диапазоне %d-%d", edit.start, edit.end)
+			continue
+		}
+		sb.WriteString(content[last:edit.start])
+		sb.WriteString(edit.text)
+		last = edit.end
 	}
-	return fn.Name
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// replacementText returns the text that replaces targetFn, rendered in the
+// target's shape. The doc comment is swapped together with the body, except
+// when the source has none or when KeepTargetDoc is set: then the target's doc
+// stays in place.
+func (fr *FunctionReplacer) replacementText(targetFn, sourceFn Function) string {
+	doc, spec := declParts(sourceFn)
+	if fr.KeepTargetDoc || sourceFn.Doc == "" {
+		doc, _ = declParts(targetFn)
+	}
+	return renderDecl(sourceFn, doc, spec, targetFn)
+}
+
+// declParts splits an entry into its doc comment and its text, both dedented
+// to column zero so that they can be rendered either standalone or inside a
+// grouped block.
+func declParts(fn Function) (doc, spec string) {
+	doc = dedentTail(fn.Doc, fn.Indent, nil)
+	spec = dedentTail(strings.TrimPrefix(fn.FullText, fn.Doc), fn.Indent, fn.Literal)
+	return doc, spec
+}
+
+// renderDecl renders the doc and spec of source in the shape of like: inside
+// like's grouped block, or standalone when like is not grouped, in both cases
+// with like's indentation. The type/var/const keyword is added or dropped when
+// a spec moves between the two; everything else is rendered as it is.
+func renderDecl(source Function, doc, spec string, like Function) string {
+	// The literal lines of source are counted from the start of spec
+	docLines := strings.Count(doc, "\n")
+	literal := make([]int, len(source.Literal))
+	for i, line := range source.Literal {
+		literal[i] = docLines + line
+	}
+
+	switch {
+	case source.Group == "" && like.Group == "":
+		return indentTail(doc+spec, like.Indent, literal)
+	case like.Group == "":
+		return doc + string(source.Kind) + " " + spec
+	case source.Group == "":
+		spec = strings.TrimSpace(strings.TrimPrefix(spec, string(source.Kind)))
+	}
+	return indentTail(doc+spec, like.Indent, literal)
+}
+
+// dedentTail removes indent from every line but the first, which starts
+// mid-line in the content an entry was extracted from. The lines listed in
+// literal are kept as they are.
+func dedentTail(text, indent string, literal []int) string {
+	if indent == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if !slices.Contains(literal, i) {
+			lines[i] = strings.TrimPrefix(lines[i], indent)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentTail adds indent to every non-empty line but the first and those
+// listed in literal.
+func indentTail(text, indent string, literal []int) string {
+	if indent == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" && !slices.Contains(literal, i) {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func readFile(filename string) (string, error) {
@@ -412,14 +567,18 @@ func readFromClipboard() (string, error) {
 	return content, nil
 }
 
-func writeFile(filename, content string) error {
-	if strings.TrimSpace(content) != "" {
-		normalizedContent := strings.ReplaceAll(content, "\r\n", "\n")
-		trimmedContent := strings.TrimRight(normalizedContent, "\n")
-		content = trimmedContent + "\n"
-	} else {
-		content = ""
+// normalizeOutput returns content exactly as writeFile stores it: LF line
+// endings and a single trailing newline.
+func normalizeOutput(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return ""
 	}
+	normalizedContent := strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.TrimRight(normalizedContent, "\n") + "\n"
+}
+
+func writeFile(filename, content string) error {
+	content = normalizeOutput(content)
 
 	err := os.WriteFile(filename, []byte(content), 0644)
 	if err != nil {
@@ -428,39 +587,59 @@ func writeFile(filename, content string) error {
 	return nil
 }
 
-func isGoFile(filename string) bool {
-	return strings.HasSuffix(strings.ToLower(filename), ".go")
+// options holds the optional flags that may precede the file arguments.
+type options struct {
+	keepTargetDoc bool
+	pruneImports  bool
+	noFormat      bool
+	dryRun        bool
+	showDiff      bool
+	color         bool
+	lang          string
 }
 
-func determineFileTypeFromContent(content string) bool {
-	goIndicators := []string{"func ", "package ", "import (", "type ", "var ", "go func"}
-	tsIndicators := []string{"function ", "const ", "export ", "interface ", "import ", "class ", "=>", "async function", "public ", "private ", ": void", ": string", ": number", ": boolean", "<T>"}
-
-	goScore := 0
-	tsScore := 0
-
-	normalizedContent := strings.ToLower(content)
-
-	for _, indicator := range goIndicators {
-		if strings.Contains(normalizedContent, indicator) {
-			goScore++
+// splitOptions separates known option flags from the positional arguments.
+// Everything after "--" is positional.
+func splitOptions(args []string) (options, []string) {
+	var opts options
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
 		}
-	}
-	for _, indicator := range tsIndicators {
-		if strings.Contains(normalizedContent, indicator) {
-			tsScore++
+		switch {
+		case strings.HasPrefix(arg, "--lang="):
+			opts.lang = strings.TrimPrefix(arg, "--lang=")
+			continue
+		case arg == "--lang" && i+1 < len(args):
+			opts.lang = args[i+1]
+			i++
+			continue
+		}
+		switch arg {
+		case "--keep-doc":
+			opts.keepTargetDoc = true
+		case "--prune-imports":
+			opts.pruneImports = true
+		case "--no-fmt":
+			opts.noFormat = true
+		case "--dry-run":
+			opts.dryRun = true
+		case "--diff":
+			opts.showDiff = true
+		case "--color":
+			opts.color = true
+		default:
+			rest = append(rest, arg)
 		}
 	}
-
-	if goScore == 0 && tsScore == 0 {
-		return true
-	}
-
-	return goScore > tsScore
+	return opts, rest
 }
 
 func parseArgs() (sourceFile string, targetFile string, useClipboard bool, valid bool) {
-	args := os.Args[1:]
+	_, args := splitOptions(os.Args[1:])
 
 	if len(args) == 0 {
 		return "", "", false, false
@@ -518,6 +697,14 @@ func showUsage() {
 	fmt.Printf("  %s <исходный_файл> <целевой_файл>             # Из файла в файл\n", cmd)
 	fmt.Printf("  %s -- <целевой_файл>                          # Исходник из буфера обмена (с разделителем)\n", cmd)
 	fmt.Printf("  %s -- <исходный_файл> <целевой_файл>          # Из файла в файл (с разделителем)\n", cmd)
+	fmt.Println("\nОпции (указываются перед файлами):")
+	fmt.Println("  --dry-run          Показать unified diff изменений и ничего не записывать")
+	fmt.Println("  --diff             Показать unified diff после записи")
+	fmt.Println("  --color            Раскрасить diff")
+	fmt.Println("  --keep-doc         Сохранять doc-комментарии целевого файла при замене функций")
+	fmt.Println("  --prune-imports    Удалять импорты Go, которые перестали использоваться")
+	fmt.Println("  --no-fmt           Не форматировать результат для Go через gofmt (проверка синтаксиса остаётся)")
+	fmt.Println("  --lang <язык>      Язык файлов: go, ts, tsx, js, jsx, py, rs, java, cs, kt, c или cpp (по умолчанию по расширению)")
 	fmt.Println("\nПримеры:")
 	fmt.Printf("  %s target.go\n", cmd)
 	fmt.Printf("  %s --clipboard target.go\n", cmd)
@@ -541,24 +728,40 @@ func main() {
 		log.Printf("Синхронизация функций из %s в %s\n", sourceFile, targetFile)
 	}
 
+	opts, _ := splitOptions(os.Args[1:])
 	replacer := NewFunctionReplacer()
+	replacer.KeepTargetDoc = opts.keepTargetDoc
+	replacer.PruneImports = opts.pruneImports
+	replacer.NoFormat = opts.noFormat
 	var sourceContent string
-	var sourceIsGo bool
+	var sourceLang Language
 	var err error
 
+	targetLang, err := detectLanguage(targetFile, opts.lang)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
 	if useClipboard {
 		sourceContent, err = readFromClipboard()
 		if err != nil {
 			log.Fatalf("Ошибка чтения из буфера обмена: %v", err)
 		}
-		sourceIsGo = determineFileTypeFromContent(sourceContent)
-		log.Printf("Обнаружен тип исходного кода (из буфера): %s\n", map[bool]string{true: "Go", false: "TypeScript"}[sourceIsGo])
+		sourceLang = detectLanguageFromContent(sourceContent, targetLang)
+		log.Printf("Обнаружен тип исходного кода (из буфера): %s\n", sourceLang.Family())
+		if sourceLang.Family() == targetLang.Family() {
+			// A snippet has no extension to tell e.g. TSX from TypeScript
+			sourceLang = targetLang
+		}
 	} else {
 		sourceContent, err = readFile(sourceFile)
 		if err != nil {
 			log.Fatalf("Ошибка чтения исходного файла '%s': %v", sourceFile, err)
 		}
-		sourceIsGo = isGoFile(sourceFile)
+		sourceLang, err = detectLanguage(sourceFile, opts.lang)
+		if err != nil {
+			log.Fatalf("Ошибка: %v", err)
+		}
 	}
 
 	if _, statErr := os.Stat(targetFile); os.IsNotExist(statErr) {
@@ -574,31 +777,68 @@ func main() {
 		log.Printf("Целевой файл %s не найден, будет создан новый.", targetFile)
 	}
 
-	targetIsGo := isGoFile(targetFile)
-
-	if sourceIsGo != targetIsGo {
-		sourceTypeStr := "TypeScript"
-		if sourceIsGo {
-			sourceTypeStr = "Go"
-		}
-		targetTypeStr := "TypeScript"
-		if targetIsGo {
-			targetTypeStr = "Go"
-		}
-		log.Fatalf("Типы исходного (%s) и целевого (%s) файлов не совпадают. Оба должны быть Go или оба TypeScript.", sourceTypeStr, targetTypeStr)
+	if sourceLang.Family() != targetLang.Family() {
+		log.Fatalf("Типы исходного (%s) и целевого (%s) файлов не совпадают. Оба файла должны быть на одном языке.", sourceLang.Family(), targetLang.Family())
 	}
 
-	sourceFunctions, err := replacer.extractFunctions(sourceContent, sourceIsGo)
+	sourceFunctions, err := sourceLang.Extract(sourceContent)
 	if err != nil {
 		log.Fatalf("Ошибка извлечения функций из исходного кода: %v", err)
 	}
-	log.Printf("Найдено %d функций в исходном коде.\n", len(sourceFunctions))
+	log.Printf("Найдено %d объявлений в исходном коде.\n", len(sourceFunctions))
+
+	updatedContent := replacer.replaceFunctions(targetContentOriginal, sourceFunctions, targetLang)
+	updatedContent, err = targetLang.Finish(replacer, updatedContent, sourceContent, sourceFunctions)
+	if err != nil {
+		log.Fatalf("Целевой файл '%s' не изменён: %v", targetFile, err)
+	}
+
+	// The diff is only computed when it is shown
+	wantDiff := opts.dryRun || opts.showDiff
+	var diff string
+	if wantDiff {
+		diff = unifiedDiff(targetFile, targetContentOriginal, normalizeOutput(updatedContent), opts.color)
+	}
 
-	updatedContent := replacer.replaceFunctions(targetContentOriginal, sourceFunctions, targetIsGo)
+	// The paired header gets the new signatures of the synced definitions
+	var headerFile, headerContent, updatedHeader string
+	if syncer, ok := targetLang.(headerSyncer); ok {
+		headerFile = syncer.PairedHeader(targetFile)
+	}
+	if headerFile != "" {
+		headerContent, err = readFile(headerFile)
+		if err != nil {
+			log.Fatalf("Ошибка чтения заголовочного файла '%s': %v", headerFile, err)
+		}
+		updatedHeader = targetLang.(headerSyncer).SyncDeclarations(headerContent, sourceFunctions)
+		if updatedHeader == headerContent {
+			headerFile = ""
+		} else if wantDiff {
+			diff += unifiedDiff(headerFile, headerContent, normalizeOutput(updatedHeader), opts.color)
+		}
+	}
+
+	if opts.dryRun {
+		fmt.Print(diff)
+		if diff == "" {
+			log.Printf("Изменений нет.")
+		}
+		log.Printf("Режим --dry-run: файл %s не изменён.\n", targetFile)
+		return
+	}
 
 	if err := writeFile(targetFile, updatedContent); err != nil {
 		log.Fatalf("Ошибка записи в целевой файл '%s': %v", targetFile, err)
 	}
+	if headerFile != "" {
+		if err := writeFile(headerFile, updatedHeader); err != nil {
+			log.Fatalf("Ошибка записи в заголовочный файл '%s': %v", headerFile, err)
+		}
+		log.Printf("Объявления обновлены в %s.\n", headerFile)
+	}
+	if opts.showDiff {
+		fmt.Print(diff)
+	}
 
 	log.Printf("Синхронизация завершена успешно для %s.\n", targetFile)
 }
@@ -5,25 +5,23 @@ import (
 	"testing"
 )
 
-func TestFunctionReplacer_extractFunctions(t *testing.T) {
-	replacer := NewFunctionReplacer()
-
+func TestLanguageExtract_Functions(t *testing.T) {
 	tests := []struct {
 		name     string
 		filename string
-		isGoFile bool
+		lang     Language
 		expected []string // Expected function names in order of appearance
 	}{
 		{
 			name:     "User clipboard Go source - clean",
 			filename: "testdata/user_clipboard_source.go",
-			isGoFile: true,
+			lang:     langGo,
 			expected: []string{"RequestPremiumSession"},
 		},
 		{
 			name:     "Go with mixed comments and unclosed block",
 			filename: "testdata/go_mixed_comments.go",
-			isGoFile: true,
+			lang:     langGo,
 			// NOTE: The file testdata/go_mixed_comments.go, in its original and current form,
 			// has the critical "supposedly unclosed" comment block actually CLOSED with a "*/".
 			// Therefore, PotentiallyAffectedByUnclosedComment and AnotherUnaffectedFunctionAfterPotentialClosure
@@ -33,51 +31,63 @@ func TestFunctionReplacer_extractFunctions(t *testing.T) {
 		{
 			name:     "Go functions - simple",
 			filename: "testdata/source.go",
-			isGoFile: true,
+			lang:     langGo,
 			expected: []string{"Hello", "Serve"},
 		},
 		{
 			name:     "TypeScript functions - simple",
 			filename: "testdata/source.ts",
-			isGoFile: false,
+			lang:     langTypeScript,
 			expected: []string{"greet", "serve"},
 		},
 		{
 			name:     "Go functions - complex",
 			filename: "testdata/source_complex.go",
-			isGoFile: true,
+			lang:     langGo,
 			// Order: SimpleFunc, MethodWithArgs, AnotherFunc, SimpleFuncNeighbor, Serve, FuncWithNoReceiver
 			expected: []string{"SimpleFunc", "MethodWithArgs", "AnotherFunc", "SimpleFuncNeighbor", "Serve", "FuncWithNoReceiver"},
 		},
 		{
 			name:     "TypeScript functions - complex",
 			filename: "testdata/source_complex.ts",
-			isGoFile: false,
+			lang:     langTypeScript,
 			// Order: simpleTsFunc, arrowTsFunc, classMethod, staticTsMethod, asyncTsFunc, utilityTsFunc, newSourceOnlyTsFunc, genericTsFunc
 			expected: []string{"simpleTsFunc", "arrowTsFunc", "classMethod", "staticTsMethod", "asyncTsFunc", "utilityTsFunc", "newSourceOnlyTsFunc", "genericTsFunc"},
 		},
+		{
+			name:     "Go signatures with braces before the body",
+			filename: "testdata/go_tricky_signatures.go",
+			lang:     langGo,
+			expected: []string{"AcceptsEmptyInterface", "ReturnsAnonymousStruct", "MentionsFuncInString", "TakesCallback"},
+		},
+		{
+			name:     "Go clipboard fragment without package clause",
+			filename: "testdata/go_fragment.go",
+			lang:     langGo,
+			expected: []string{"FragmentFirst", "FragmentMethod"},
+		},
 		{
 			name:     "Empty Go file",
 			filename: "testdata/empty.go",
-			isGoFile: true,
+			lang:     langGo,
 			expected: []string{},
 		},
 		{
 			name:     "Empty TS file",
 			filename: "testdata/empty.ts",
-			isGoFile: false,
+			lang:     langTypeScript,
 			expected: []string{},
 		},
 		{
 			name:     "Go file with no functions",
 			filename: "testdata/no_funcs.go",
-			isGoFile: true,
+			lang:     langGo,
 			expected: []string{},
 		},
 		{
 			name:     "TS file with no functions",
 			filename: "testdata/no_funcs.ts",
-			isGoFile: false,
+			lang:     langTypeScript,
 			expected: []string{},
 		},
 	}
@@ -96,10 +106,16 @@ func TestFunctionReplacer_extractFunctions(t *testing.T) {
 
 			t.Logf("Содержимое файла %s:\n%s", tt.filename, content)
 
-			functions, err := replacer.extractFunctions(content, tt.isGoFile)
+			declarations, err := tt.lang.Extract(content)
 			if err != nil {
 				t.Fatalf("Ошибка извлечения функций: %v", err)
 			}
+			var functions []Function
+			for _, decl := range declarations {
+				if decl.Kind == DeclFunc {
+					functions = append(functions, decl)
+				}
+			}
 
 			t.Logf("Найденные функции (%d):", len(functions))
 			extractedNames := make([]string, len(functions))
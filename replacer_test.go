@@ -7,7 +7,6 @@ import (
 	"testing"
 )
 
-
 // TestFunctionReplacer_replaceFunctions_Go and TestFunctionReplacer_replaceFunctions_TypeScript
 // are good for focused checks, but TestEndToEnd_Integration will cover broader scenarios.
 // I'm keeping them as they are, assuming their original target files allow them to pass.
@@ -29,12 +28,12 @@ func TestFunctionReplacer_replaceFunctions_Go(t *testing.T) {
 		t.Fatalf("Не удалось прочитать target.go: %v", err)
 	}
 
-	sourceFunctions, err := replacer.extractFunctions(sourceContent, true)
+	sourceFunctions, err := langGo.Extract(sourceContent)
 	if err != nil {
 		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
 	}
 
-	result := replacer.replaceFunctions(targetContent, sourceFunctions, true)
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langGo)
 
 	if !strings.Contains(result, "Hello from source!") {
 		t.Error("Функция Hello не была заменена")
@@ -64,12 +63,12 @@ func TestFunctionReplacer_replaceFunctions_TypeScript(t *testing.T) {
 		t.Fatalf("Не удалось прочитать target.ts: %v", err)
 	}
 
-	sourceFunctions, err := replacer.extractFunctions(sourceContent, false)
+	sourceFunctions, err := langTypeScript.Extract(sourceContent)
 	if err != nil {
 		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
 	}
 
-	result := replacer.replaceFunctions(targetContent, sourceFunctions, false)
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langTypeScript)
 
 	if !strings.Contains(result, "Hello from source!") { // Assuming source.ts has 'greet' -> "Hello from source!"
 		t.Error("Функция greet не была заменена")
@@ -85,52 +84,113 @@ func TestFunctionReplacer_replaceFunctions_TypeScript(t *testing.T) {
 	}
 }
 
-func TestDetermineFileTypeFromContent(t *testing.T) {
+func TestDetectLanguageFromContent(t *testing.T) {
 	tests := []struct {
-		name     string
-		filename string
-		expected bool // true для Go, false для TypeScript
+		name      string
+		filename  string
+		content   string   // used instead of the file when set
+		preferred Language // the target's language
+		expected  Language
 	}{
 		{
 			name:     "Go content",
 			filename: "testdata/source.go",
-			expected: true,
+			expected: langGo,
 		},
 		{
 			name:     "TypeScript content",
 			filename: "testdata/source.ts",
-			expected: false,
+			expected: langTypeScript,
 		},
 		{
 			name:     "Complex Go content",
 			filename: "testdata/source_complex.go",
-			expected: true,
+			expected: langGo,
 		},
 		{
 			name:     "Complex TypeScript content",
 			filename: "testdata/source_complex.ts",
-			expected: false,
+			expected: langTypeScript,
+		},
+		{
+			name:     "Python content",
+			filename: "testdata/py_source.py",
+			expected: langPython,
+		},
+		{
+			name:     "Rust content",
+			filename: "testdata/rs_source.rs",
+			expected: langRust,
+		},
+		{
+			name:     "Java content",
+			filename: "testdata/java_source.java",
+			expected: langJava,
+		},
+		{
+			name:     "Kotlin content",
+			filename: "testdata/kt_source.kt",
+			expected: langKotlin,
 		},
 		{
 			name:     "Empty Go file (should probably default or error, testing typical heuristic)",
 			filename: "testdata/empty.go", // Content based, so empty is ambiguous. Assuming it might default to Go or TS based on other clues or return a specific error/default.
-			expected: true, // Or false, depending on determineFileTypeFromContent's behavior for empty strings. Adjust if needed.
+			expected: langGo,              // Go is the fallback when nothing matches.
+		},
+		{
+			name:     "Go snippet calling fmt.Println",
+			content:  "func Foo() {\n\tfmt.Println(\"x\")\n}\n",
+			expected: langGo,
+		},
+		{
+			name:      "Target's language wins when the snippet looks like it",
+			content:   "val x = listOf(1)\n",
+			preferred: langKotlin,
+			expected:  langKotlin,
+		},
+		{
+			name:      "Target's language that does not match is ignored",
+			content:   "def greet(name):\n    print(f\"hi {name}\")\n",
+			preferred: langGo,
+			expected:  langPython,
+		},
+		{
+			name:      "Go snippet with a constant is not taken for the TypeScript target",
+			content:   "const maxRetries = 3\n\ntype Retrier struct{ Op func() error }\n\nfunc (r *Retrier) Run() error {\n\tfor i := 0; i < maxRetries; i++ {\n\t\tif err := r.Op(); err == nil {\n\t\t\treturn nil\n\t\t}\n\t}\n\treturn nil\n}\n",
+			preferred: langTypeScript,
+			expected:  langGo,
+		},
+		{
+			name:      "Python snippet with an import is not taken for the TypeScript target",
+			content:   "import os\n\ndef home():\n    return os.environ.get(\"HOME\")\n",
+			preferred: langTypeScript,
+			expected:  langPython,
+		},
+		{
+			name:      "TypeScript snippet is not taken for the Go target",
+			content:   "export function greet(name: string): string {\n  const greeting = `Hello, ${name}`;\n  return greeting;\n}\n",
+			preferred: langGo,
+			expected:  langTypeScript,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if _, err := os.Stat(tt.filename); os.IsNotExist(err) {
-				t.Fatalf("Test file %s does not exist.", tt.filename)
-			}
-			content, err := readFile(tt.filename)
-			if err != nil {
-				t.Fatalf("Не удалось прочитать файл %s: %v", tt.filename, err)
+			content := tt.content
+			if tt.filename != "" {
+				if _, err := os.Stat(tt.filename); os.IsNotExist(err) {
+					t.Fatalf("Test file %s does not exist.", tt.filename)
+				}
+				var err error
+				content, err = readFile(tt.filename)
+				if err != nil {
+					t.Fatalf("Не удалось прочитать файл %s: %v", tt.filename, err)
+				}
 			}
 
-			result := determineFileTypeFromContent(content)
-			if result != tt.expected {
-				t.Errorf("Для файла %s: Ожидался тип %v, получен %v", tt.filename, tt.expected, result)
+			result := detectLanguageFromContent(content, tt.preferred)
+			if result.Family() != tt.expected.Family() {
+				t.Errorf("Для файла %s: Ожидался тип %v, получен %v", tt.filename, tt.expected.Family(), result.Family())
 			}
 		})
 	}
@@ -138,67 +198,83 @@ func TestDetermineFileTypeFromContent(t *testing.T) {
 
 func TestParseArgs(t *testing.T) {
 	tests := []struct {
-		name            string
-		args            []string
-		expectedSource  string
-		expectedTarget  string
-		expectedClip    bool
-		expectedValid   bool
+		name           string
+		args           []string
+		expectedSource string
+		expectedTarget string
+		expectedClip   bool
+		expectedValid  bool
 	}{
 		{
-			name:            "Target only (clipboard source)",
-			args:            []string{"target.go"},
-			expectedSource:  "",
-			expectedTarget:  "target.go",
-			expectedClip:    true,
-			expectedValid:   true,
+			name:           "Target only (clipboard source)",
+			args:           []string{"target.go"},
+			expectedSource: "",
+			expectedTarget: "target.go",
+			expectedClip:   true,
+			expectedValid:  true,
 		},
 		{
-			name:            "Source and target",
-			args:            []string{"source.go", "target.go"},
-			expectedSource:  "source.go",
-			expectedTarget:  "target.go",
-			expectedClip:    false,
-			expectedValid:   true,
+			name:           "Source and target",
+			args:           []string{"source.go", "target.go"},
+			expectedSource: "source.go",
+			expectedTarget: "target.go",
+			expectedClip:   false,
+			expectedValid:  true,
 		},
 		{
-			name:            "With separator - clipboard",
-			args:            []string{"--", "target.go"},
-			expectedSource:  "",
-			expectedTarget:  "target.go",
-			expectedClip:    true,
-			expectedValid:   true,
+			name:           "With separator - clipboard",
+			args:           []string{"--", "target.go"},
+			expectedSource: "",
+			expectedTarget: "target.go",
+			expectedClip:   true,
+			expectedValid:  true,
 		},
 		{
-			name:            "With separator - files",
-			args:            []string{"--", "source.go", "target.go"},
-			expectedSource:  "source.go",
-			expectedTarget:  "target.go",
-			expectedClip:    false,
-			expectedValid:   true,
+			name:           "With separator - files",
+			args:           []string{"--", "source.go", "target.go"},
+			expectedSource: "source.go",
+			expectedTarget: "target.go",
+			expectedClip:   false,
+			expectedValid:  true,
 		},
 		{
-			name:            "Invalid - only separator",
-			args:            []string{"--"},
-			expectedSource:  "",
-			expectedTarget:  "",
-			expectedClip:    false,
-			expectedValid:   false,
+			name:           "Option flags are not positional",
+			args:           []string{"--keep-doc", "source.go", "target.go"},
+			expectedSource: "source.go",
+			expectedTarget: "target.go",
+			expectedClip:   false,
+			expectedValid:  true,
 		},
 		{
-			name:            "Invalid - too many args before separator",
-			args:            []string{"s.go", "t.go", "x.go", "--", "target.go"},
-			expectedValid:   false,
+			name:           "Lang option value is not positional",
+			args:           []string{"--lang", "ts", "source.txt", "target.txt"},
+			expectedSource: "source.txt",
+			expectedTarget: "target.txt",
+			expectedClip:   false,
+			expectedValid:  true,
 		},
 		{
-			name:            "Invalid - too many args after separator",
-			args:            []string{"--", "s.go", "t.go", "x.go"},
-			expectedValid:   false,
+			name:           "Invalid - only separator",
+			args:           []string{"--"},
+			expectedSource: "",
+			expectedTarget: "",
+			expectedClip:   false,
+			expectedValid:  false,
 		},
 		{
-			name:            "No args",
-			args:            []string{},
-			expectedValid:   false,
+			name:          "Invalid - too many args before separator",
+			args:          []string{"s.go", "t.go", "x.go", "--", "target.go"},
+			expectedValid: false,
+		},
+		{
+			name:          "Invalid - too many args after separator",
+			args:          []string{"--", "s.go", "t.go", "x.go"},
+			expectedValid: false,
+		},
+		{
+			name:          "No args",
+			args:          []string{},
+			expectedValid: false,
 		},
 	}
 
@@ -230,6 +306,60 @@ func TestParseArgs(t *testing.T) {
 	}
 }
 
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		filename string
+		override string
+		expected Language
+		wantErr  bool
+	}{
+		{filename: "main.go", expected: langGo},
+		{filename: "api.ts", expected: langTypeScript},
+		{filename: "api.mts", expected: langTypeScript},
+		{filename: "App.tsx", expected: langTSX},
+		{filename: "index.js", expected: langJavaScript},
+		{filename: "index.CJS", expected: langJavaScript},
+		{filename: "App.jsx", expected: langJSX},
+		{filename: "model.py", expected: langPython},
+		{filename: "stubs.pyi", expected: langPython},
+		{filename: "notes.txt", override: "py", expected: langPython},
+		{filename: "lib.rs", expected: langRust},
+		{filename: "notes.txt", override: "rs", expected: langRust},
+		{filename: "UserRepository.java", expected: langJava},
+		{filename: "OrderService.cs", expected: langCSharp},
+		{filename: "notes.txt", override: "c#", expected: langCSharp},
+		{filename: "Users.kt", expected: langKotlin},
+		{filename: "build.gradle.kts", expected: langKotlin},
+		{filename: "counter.c", expected: langC},
+		{filename: "counter.h", expected: langC},
+		{filename: "widget.cpp", expected: langCPP},
+		{filename: "widget.hpp", expected: langCPP},
+		{filename: "widget.h", override: "c++", expected: langCPP},
+		{filename: "README.md", wantErr: true},
+		{filename: "config.yaml", wantErr: true},
+		{filename: "notes.txt", override: "tsx", expected: langTSX},
+		{filename: "main.go", override: "cobol", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename+"/"+tt.override, func(t *testing.T) {
+			lang, err := detectLanguage(tt.filename, tt.override)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Ожидалась ошибка, получен язык %s", lang.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Неожиданная ошибка: %v", err)
+			}
+			if lang != tt.expected {
+				t.Errorf("Ожидался язык %s, получен %s", tt.expected.Name(), lang.Name())
+			}
+		})
+	}
+}
+
 func TestEndToEnd_Integration(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "replacer_test_e2e")
 	if err != nil {
@@ -247,14 +377,14 @@ func TestEndToEnd_Integration(t *testing.T) {
 		name       string
 		sourceFile string
 		targetFile string
-		isGoFile   bool
+		lang       Language
 		checks     []check
 	}{
 		{
 			name:       "Go files integration - simple",
 			sourceFile: "testdata/source.go", // Contains Hello, Serve
 			targetFile: "testdata/target.go", // Contains Hello, Bye, OldServe (original prompt version, NO Serve)
-			isGoFile:   true,
+			lang:       langGo,
 			checks: []check{ // These checks assume Serve from source.go is ADDED to target.go
 				{shouldContain: "Hello from source!", description: "Функция Hello должна быть заменена"},
 				{shouldNotContain: "Hello from target!", description: "Старая функция Hello должна исчезнуть"},
@@ -268,7 +398,7 @@ func TestEndToEnd_Integration(t *testing.T) {
 			name:       "TypeScript files integration - simple",
 			sourceFile: "testdata/source.ts", // Contains greet, serve
 			targetFile: "testdata/target.ts", // Contains greet, bye, oldServe (original prompt version, NO serve method)
-			isGoFile:   false,
+			lang:       langTypeScript,
 			checks: []check{ // These checks assume 'serve' from source.ts is ADDED to target.ts
 				{shouldContain: "Hello from source!", description: "Функция greet должна быть заменена"}, // Assuming source.ts greet produces "Hello from source!"
 				{shouldNotContain: "Hello ${name} from target!", description: "Старая функция greet должна исчезнуть"},
@@ -281,7 +411,7 @@ func TestEndToEnd_Integration(t *testing.T) {
 			name:       "Go files integration - complex",
 			sourceFile: "testdata/source_complex.go",
 			targetFile: "testdata/target_complex.go",
-			isGoFile:   true,
+			lang:       langGo,
 			checks: []check{
 				// Replaced
 				{shouldContain: "New SimpleFunc from source_complex.go", description: "SimpleFunc should be replaced"},
@@ -308,7 +438,7 @@ func TestEndToEnd_Integration(t *testing.T) {
 			name:       "TypeScript files integration - complex",
 			sourceFile: "testdata/source_complex.ts",
 			targetFile: "testdata/target_complex.ts",
-			isGoFile:   false,
+			lang:       langTypeScript,
 			checks: []check{
 				// Replaced
 				{shouldContain: "New simpleTsFunc from source_complex.ts", description: "simpleTsFunc should be replaced"},
@@ -359,12 +489,12 @@ func TestEndToEnd_Integration(t *testing.T) {
 				t.Fatalf("Не удалось прочитать целевой файл %s: %v", tt.targetFile, err)
 			}
 
-			sourceFunctions, err := replacer.extractFunctions(sourceContent, tt.isGoFile)
+			sourceFunctions, err := tt.lang.Extract(sourceContent)
 			if err != nil {
 				t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
 			}
 
-			result := replacer.replaceFunctions(targetContent, sourceFunctions, tt.isGoFile)
+			result := replacer.replaceFunctions(targetContent, sourceFunctions, tt.lang)
 
 			for i, check := range tt.checks {
 				if check.shouldContain != "" && !strings.Contains(result, check.shouldContain) {
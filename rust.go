@@ -0,0 +1,506 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Token kinds of the Rust tokenizer.
+const (
+	rsIdent    = iota
+	rsLifetime // 'a, 'static
+	rsLiteral  // strings, raw strings, chars and numbers
+	rsPunct
+)
+
+type rsToken struct {
+	kind       int
+	text       string
+	start, end int
+}
+
+func isRustIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isRustIdentChar(c byte) bool {
+	return isRustIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// tokenizeRust splits content into tokens, dropping whitespace and comments.
+// Block comments nest, "'" starts either a char literal or a lifetime, and
+// raw strings such as r#"..."# end only at a quote followed by as many "#".
+func tokenizeRust(content string) []rsToken {
+	var tokens []rsToken
+	add := func(kind, start, end int) {
+		tokens = append(tokens, rsToken{kind: kind, text: content[start:end], start: start, end: end})
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				end = len(content) - i
+			}
+			i += end
+		case strings.HasPrefix(content[i:], "/*"):
+			i = skipRustBlockComment(content, i)
+		case c == '"':
+			end := skipRustString(content, i)
+			add(rsLiteral, i, end)
+			i = end
+		case c == '\'':
+			end, kind := skipRustQuote(content, i)
+			add(kind, i, end)
+			i = end
+		case isRustIdentStart(c):
+			if end := skipRustPrefixedLiteral(content, i); end > i {
+				add(rsLiteral, i, end)
+				i = end
+				continue
+			}
+			start := i
+			if strings.HasPrefix(content[i:], "r#") && i+2 < len(content) && isRustIdentStart(content[i+2]) {
+				// Raw identifier
+				i += 2
+			}
+			for i < len(content) && isRustIdentChar(content[i]) {
+				i++
+			}
+			add(rsIdent, start, i)
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(content) && isRustIdentChar(content[i]) {
+				i++
+			}
+			if i+1 < len(content) && content[i] == '.' && content[i+1] >= '0' && content[i+1] <= '9' {
+				i++
+				for i < len(content) && isRustIdentChar(content[i]) {
+					i++
+				}
+			}
+			add(rsLiteral, start, i)
+		default:
+			add(rsPunct, i, i+1)
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipRustBlockComment returns the index just past the possibly nested block
+// comment starting at i.
+func skipRustBlockComment(content string, i int) int {
+	depth := 0
+	for i < len(content) {
+		switch {
+		case strings.HasPrefix(content[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(content[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(content)
+}
+
+// skipRustString returns the index just past the string literal whose quote
+// is at i.
+func skipRustString(content string, i int) int {
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return len(content)
+}
+
+// skipRustQuote reads the char literal or lifetime starting with the "'" at
+// i and returns the index just past it with its kind. 'a' is a char while 'a
+// without a closing quote is a lifetime.
+func skipRustQuote(content string, i int) (int, int) {
+	if i+1 >= len(content) {
+		return i + 1, rsPunct
+	}
+	if content[i+1] == '\\' {
+		for j := i + 2; j < len(content) && content[j] != '\n'; j++ {
+			if content[j] == '\'' {
+				return j + 1, rsLiteral
+			}
+		}
+		return i + 1, rsPunct
+	}
+	_, size := utf8.DecodeRuneInString(content[i+1:])
+	if i+1+size < len(content) && content[i+1+size] == '\'' {
+		return i + 2 + size, rsLiteral
+	}
+	if isRustIdentStart(content[i+1]) {
+		j := i + 1
+		for j < len(content) && isRustIdentChar(content[j]) {
+			j++
+		}
+		return j, rsLifetime
+	}
+	return i + 1, rsPunct
+}
+
+// skipRustPrefixedLiteral returns the index just past a byte, C or raw string
+// literal or a byte char starting at i, such as b"..", br#".."# or b'x', or
+// i when there is none.
+func skipRustPrefixedLiteral(content string, i int) int {
+	j := i
+	if content[j] == 'b' || content[j] == 'c' {
+		j++
+	}
+	if j < len(content) && content[j] == 'r' {
+		k := j + 1
+		for k < len(content) && content[k] == '#' {
+			k++
+		}
+		if k < len(content) && content[k] == '"' {
+			closing := "\"" + strings.Repeat("#", k-j-1)
+			if end := strings.Index(content[k+1:], closing); end != -1 {
+				return k + 1 + end + len(closing)
+			}
+			return len(content)
+		}
+		return i
+	}
+	if j == i || j >= len(content) {
+		return i
+	}
+	switch {
+	case content[j] == '"':
+		return skipRustString(content, j)
+	case content[j] == '\'' && content[i] == 'b':
+		if end, kind := skipRustQuote(content, j); kind == rsLiteral {
+			return end
+		}
+	}
+	return i
+}
+
+func (t rsToken) tokenText() string { return t.text }
+
+func (t rsToken) tokenClass() tokenClass {
+	switch t.kind {
+	case rsIdent:
+		return classIdent
+	case rsPunct:
+		return classPunct
+	}
+	return classOther
+}
+
+// rsParser recognizes the items of a Rust source: functions, impl and trait
+// blocks with their methods, modules, and type, const and static items.
+type rsParser struct {
+	tokenIndex
+	content string
+	tokens  []rsToken
+	decls   []Function
+}
+
+// extractRustDeclarations returns the items of content. Methods are keyed by
+// their impl block, e.g. "Foo.new" or "Display for Foo.fmt", and items of
+// inline modules by the module path.
+func extractRustDeclarations(content string) []Function {
+	p := &rsParser{content: content, tokens: tokenizeRust(content)}
+	p.tokenIndex = newTokenIndex(p.tokens)
+	p.parseItems(0, len(p.tokens), "")
+	return p.decls
+}
+
+// skipItem returns the index just past the item or statement at i: its ";"
+// or the "}" of its block.
+func (p *rsParser) skipItem(i, end int) int {
+	for j := i; j < end; j++ {
+		switch {
+		case p.is(j, ";"):
+			return j + 1
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j, end)
+		case p.is(j, "{"):
+			return p.closing(j, end) + 1
+		}
+	}
+	return end
+}
+
+// rsQualifiers may precede the keyword of a function, impl or trait item.
+var rsQualifiers = map[string]bool{"async": true, "unsafe": true, "default": true, "auto": true}
+
+// parseItems walks the items in tokens[from:to].
+func (p *rsParser) parseItems(from, to int, container string) {
+	for i := from; i < to; {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+		k := i
+		for p.is(k, "#") && (p.is(k+1, "[") || p.is(k+1, "!") && p.is(k+2, "[")) {
+			if p.is(k+1, "!") {
+				k++
+			}
+			k = p.closing(k+1, to) + 1
+		}
+		if p.is(k, "pub") {
+			k++
+			if p.is(k, "(") {
+				k = p.closing(k, to) + 1
+			}
+		}
+		for {
+			switch {
+			case p.is(k, "const") && (p.is(k+1, "fn") || p.is(k+1, "unsafe") || p.is(k+1, "async") || p.is(k+1, "extern")):
+				k++
+				continue
+			case p.isIdent(k) && rsQualifiers[p.tokens[k].text] && !p.is(k+1, "!"):
+				k++
+				continue
+			case p.is(k, "extern") && (p.is(k+1, "fn") || k+2 < to && p.tokens[k+1].kind == rsLiteral && p.is(k+2, "fn")):
+				k++
+				if !p.is(k, "fn") {
+					k++
+				}
+				continue
+			}
+			break
+		}
+
+		next := -1
+		switch {
+		case p.is(k, "fn") && p.isIdent(k+1):
+			next = p.parseFunction(i, k, to, container)
+		case p.is(k, "impl"):
+			next = p.parseImpl(i, k, to, container)
+		case p.is(k, "trait") && p.isIdent(k+1):
+			next = p.parseBlock(i, k+2, to, container, p.tokens[k+1].text, DeclClass)
+		case p.is(k, "mod") && p.isIdent(k+1) && p.is(k+2, "{"):
+			next = p.parseBlock(i, k+2, to, container, p.tokens[k+1].text, DeclNamespace)
+		case (p.is(k, "struct") || p.is(k, "enum") || p.is(k, "union") || p.is(k, "type")) && p.isIdent(k+1):
+			next = p.parseSimpleItem(i, k, to, container, DeclType)
+		case p.is(k, "const") && p.isIdent(k+1) && !p.is(k+1, "_"):
+			next = p.parseSimpleItem(i, k, to, container, DeclConst)
+		case p.is(k, "static") && p.isIdent(k+1):
+			name := k
+			if p.is(name+1, "mut") {
+				name++
+			}
+			next = p.parseSimpleItem(i, name, to, container, DeclVar)
+		}
+		if next > i {
+			i = next
+			continue
+		}
+		i = p.skipItem(k, to)
+	}
+}
+
+// parseFunction handles "fn name<T>(...) -> R where ... { ... }" whose keyword
+// is at k and whose attributes start at first. Bodiless signatures, as in
+// traits, end at their ";".
+func (p *rsParser) parseFunction(first, k, end int, container string) int {
+	name := p.tokens[k+1].text
+	for j := k + 2; j < end; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j, end)
+		case p.is(j, "<"):
+			j = p.skipAngles(j, end) - 1
+		case p.is(j, ";"):
+			p.addDecl(name, container, DeclFunc, first, j)
+			return j + 1
+		case p.is(j, "{"):
+			last := p.closing(j, end)
+			p.addDecl(name, container, DeclFunc, first, last)
+			return last + 1
+		}
+	}
+	return -1
+}
+
+// parseImpl handles "impl<T> Trait<T> for Type<T> where ... { ... }" whose
+// keyword is at k. The block is named "Type" or "Trait for Type" with paths
+// and the type's generic arguments dropped, so that "impl<T> fmt::Display
+// for Foo<T>" becomes "Display for Foo".
+func (p *rsParser) parseImpl(first, k, end int, container string) int {
+	j := k + 1
+	if p.is(j, "<") {
+		j = p.skipAngles(j, end)
+	}
+	headStart := j
+	forIdx := -1
+	for j < end && !p.is(j, "{") && !p.is(j, "where") && !p.is(j, ";") {
+		switch {
+		case p.is(j, "<"):
+			j = p.skipAngles(j, end)
+			continue
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j, end)
+		case p.is(j, "for"):
+			forIdx = j
+		}
+		j++
+	}
+	headEnd := j
+	for j < end && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	if !p.is(j, "{") || headStart == headEnd {
+		return -1
+	}
+
+	name := p.rustTypeName(headStart, headEnd, false)
+	if forIdx != -1 {
+		name = p.rustTypeName(headStart, forIdx, true) + " for " + p.rustTypeName(forIdx+1, headEnd, false)
+	}
+	return p.parseBlock(first, j, end, container, name, DeclImpl)
+}
+
+// rustTypeName renders the type in tokens[from:to] without its leading path.
+// The generic arguments of a trait are kept since a type may implement e.g.
+// From<u32> and From<String>; those of a self type are dropped.
+func (p *rsParser) rustTypeName(from, to int, keepGenerics bool) string {
+	// Drop the path before the last "::" outside generic arguments
+	start := from
+	for j := from; j < to; j++ {
+		switch {
+		case p.is(j, "<"):
+			j = p.skipAngles(j, to) - 1
+		case p.is(j, ":") && p.is(j+1, ":"):
+			start = j + 2
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	for j := start; j < to; j++ {
+		if p.is(j, "<") && !keepGenerics {
+			j = p.skipAngles(j, to) - 1
+			continue
+		}
+		tok := p.tokens[j]
+		if sb.Len() > 0 && tok.kind != rsPunct && j > start && p.tokens[j-1].kind != rsPunct {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.text)
+	}
+	return sb.String()
+}
+
+// parseBlock records the impl, trait or module item from first to the block
+// opened at the "{" at or after open, and walks its items with the block as
+// container.
+func (p *rsParser) parseBlock(first, open, end int, container, name string, kind DeclKind) int {
+	for open < end && !p.is(open, "{") {
+		switch {
+		case p.is(open, ";"):
+			return -1
+		case p.is(open, "<"):
+			open = p.skipAngles(open, end)
+			continue
+		case p.is(open, "(") || p.is(open, "["):
+			open = p.closing(open, end)
+		}
+		open++
+	}
+	if open >= end {
+		return -1
+	}
+	last := p.closing(open, end)
+	p.addDecl(name, container, kind, first, last)
+	p.parseItems(open+1, last, qualify(container, name))
+	return last + 1
+}
+
+// parseSimpleItem handles a struct, enum, union, type alias, const or static
+// whose name follows the keyword at k.
+func (p *rsParser) parseSimpleItem(first, k, end int, container string, kind DeclKind) int {
+	last := p.skipItem(k, end) - 1
+	if last <= k {
+		return -1
+	}
+	if p.is(last, "}") && p.is(last+1, ";") {
+		last++
+	}
+	p.addDecl(p.tokens[k+1].text, container, kind, first, last)
+	return last + 1
+}
+
+// addDecl records the item spanning tokens[first:last+1] with the comments
+// directly above it.
+func (p *rsParser) addDecl(name, container string, kind DeclKind, first, last int) {
+	lo := 0
+	if first > 0 {
+		lo = p.tokens[first-1].end
+	}
+	pos := p.tokens[first].start
+	start := tsLeadingCommentStart(p.content, lo, pos)
+	stop := p.tokens[last].end
+	lineStart := strings.LastIndexByte(p.content[:start], '\n') + 1
+	p.decls = append(p.decls, Function{
+		Name:      name,
+		Kind:      kind,
+		Container: container,
+		Doc:       p.content[start:pos],
+		FullText:  p.content[start:stop],
+		StartPos:  start,
+		EndPos:    stop,
+		Indent:    p.content[lineStart:start],
+	})
+}
+
+// rustLanguage syncs Rust sources.
+type rustLanguage struct{}
+
+var langRust = rustLanguage{}
+
+func (rustLanguage) Name() string         { return "rust" }
+func (rustLanguage) Family() string       { return "Rust" }
+func (rustLanguage) Aliases() []string    { return []string{"rs"} }
+func (rustLanguage) Extensions() []string { return []string{".rs"} }
+
+// rsIndicators are the snippets that make content look like Rust.
+var rsIndicators = []string{"fn ", "let mut ", "impl ", "&self", "&mut ", "#[", "pub fn", "::new(", "usize", "println!", "-> result<"}
+
+func (rustLanguage) ContentScore(content string) int {
+	return countIndicators(content, rsIndicators)
+}
+
+func (rustLanguage) Extract(content string) ([]Function, error) {
+	return extractRustDeclarations(content), nil
+}
+
+// Key qualifies items with their module and impl or trait block, e.g.
+// "Foo.new" or "Display for Foo.fmt". Impl blocks themselves are keyed like
+// "impl Foo" so that they do not clash with the type they implement.
+func (rustLanguage) Key(fn Function) string {
+	if fn.Kind == DeclImpl {
+		return "impl " + qualify(fn.Container, fn.Name)
+	}
+	return qualify(fn.Container, fn.Name)
+}
+
+// Insert places new methods before the closing brace of their impl or trait
+// block and new items of a module into that module.
+func (rustLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	insertions, rest := blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions)
+	return braceMemberEdits(targetContent, insertions), rest
+}
+
+func (rustLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	return content, nil
+}
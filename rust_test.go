@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractRustDeclarations(t *testing.T) {
+	content, err := readFile("testdata/rs_source.rs")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractRustDeclarations(content)
+
+	var keys []string
+	for _, decl := range declarations {
+		keys = append(keys, langRust.Key(decl))
+		if got := content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+			t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+		}
+	}
+	expected := []string{
+		"Token", "BRACE",
+		"impl Token", "Token.new", "Token.is_open", "Token.len",
+		"impl Display for Token", "Display for Token.fmt",
+		"impl From<&'static str> for Token", "From<&'static str> for Token.from",
+		"Visitor", "Visitor.visit", "Visitor.done",
+		"util", "util.trim",
+		"tokenize",
+	}
+	if strings.Join(keys, "|") != strings.Join(expected, "|") {
+		t.Fatalf("Ожидались ключи %v, получено %v", expected, keys)
+	}
+
+	if token := declarations[0].FullText; !strings.HasPrefix(token, "/// A parsed token with a borrowed slice of the input.\n#[derive(Debug, Clone)]\npub struct") {
+		t.Errorf("Комментарий или атрибут не вошёл в структуру Token:\n%s", token)
+	}
+	if length := declarations[5].FullText; !strings.HasPrefix(length, "#[inline]\n    pub fn len") {
+		t.Errorf("Атрибут не вошёл в метод len:\n%s", length)
+	}
+	if tokenize := declarations[15].FullText; !strings.HasSuffix(tokenize, ".collect()\n}") {
+		t.Errorf("Функция tokenize с where обрезана:\n%s", tokenize)
+	}
+}
+
+func TestExtractRustDeclarations_Lexing(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "Сырые строки с кавычками и скобками",
+			content:  "fn a() { let s = r##\"}\"# {\"##; }\nfn b() {}\n",
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Символы скобок и время жизни",
+			content:  "fn a<'a>(x: &'a str) -> char { if x.is_empty() { '}' } else { '{' } }\nfn b() -> u8 { b'}' }\n",
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Вложенные блочные комментарии",
+			content:  "/* outer /* inner } */ fn hidden() {} */\nfn a() {}\n",
+			expected: []string{"a"},
+		},
+		{
+			name:     "Макросы и внутренние атрибуты пропускаются",
+			content:  "#![allow(dead_code)]\nmacro_rules! m { () => {} }\nlazy_static! { static ref X: u8 = 1; }\nfn a() {}\n",
+			expected: []string{"a"},
+		},
+		{
+			name:     "Квалификаторы функций",
+			content:  "pub(crate) const unsafe fn a() {}\nextern \"C\" fn b() {}\npub async unsafe fn c() {}\n",
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "Пути и обобщения в impl",
+			content:  "impl<T: Clone> std::ops::Add<T> for crate::Wrapper<T> where T: Copy { fn add(self, o: T) -> Self { self } }\n",
+			expected: []string{"impl Add<T> for Wrapper", "Add<T> for Wrapper.add"},
+		},
+		{
+			name:     "Элементы вложенного модуля",
+			content:  "pub mod a { pub mod b { pub struct S; impl S { fn f() {} } } }\n",
+			expected: []string{"a", "a.b", "a.b.S", "impl a.b.S", "a.b.S.f"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, decl := range extractRustDeclarations(tt.content) {
+				keys = append(keys, langRust.Key(decl))
+			}
+			if strings.Join(keys, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Ожидались ключи %v, получено %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_Rust(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/rs_source.rs")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать rs_source.rs: %v", err)
+	}
+	targetContent, err := readFile("testdata/rs_target.rs")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать rs_target.rs: %v", err)
+	}
+	expected, err := readFile("testdata/rs_expected.rs")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать rs_expected.rs: %v", err)
+	}
+
+	sourceDeclarations, err := langRust.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langRust)
+	if result != expected {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+	}
+
+	if again := replacer.replaceFunctions(result, sourceDeclarations, langRust); again != result {
+		t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+	}
+}
@@ -0,0 +1,23 @@
+#include <stdio.h>
+#include "counter.h"
+
+static int clamp(int v, int hi);
+
+typedef struct {
+    long value;
+    long limit;
+} counter_state;
+
+/* Adds step to the counter and returns the new value. */
+long counter_add(struct counter *c, long step) {
+    c->value += step;
+    return c->value;
+}
+
+void counter_reset(struct counter *c) {
+    c->value = 0;
+}
+
+static int clamp(int v, int hi) {
+    return v < 0 ? 0 : (v > hi ? hi : v);
+}
@@ -0,0 +1,16 @@
+#include "counter.h"
+
+/* Adds step to the counter and returns the new value. */
+long counter_add(struct counter *c, long step) {
+    c->value += step;
+    return c->value;
+}
+
+typedef struct {
+    long value;
+    long limit;
+} counter_state;
+
+static int clamp(int v, int hi) {
+    return v < 0 ? 0 : (v > hi ? hi : v);
+}
@@ -0,0 +1,21 @@
+#include <stdio.h>
+#include "counter.h"
+
+static int clamp(int v);
+
+typedef struct {
+    long value;
+} counter_state;
+
+int counter_add(struct counter *c, int step) {
+    c->value += step;
+    return (int)c->value;
+}
+
+void counter_reset(struct counter *c) {
+    c->value = 0;
+}
+
+static int clamp(int v) {
+    return v < 0 ? 0 : v;
+}
@@ -0,0 +1,24 @@
+#pragma once
+
+#include <string>
+
+namespace ui {
+
+class Widget {
+public:
+    explicit Widget(int width, std::string title);
+    ~Widget();
+
+    template <typename T>
+    T clamp(T value, T lo, T hi) const noexcept;
+
+    int resize(int w);
+    int resize(int w, int h);
+
+private:
+    int width_;
+    int height_;
+    std::string title_;
+};
+
+}  // namespace ui
@@ -0,0 +1,11 @@
+namespace ui {
+
+void Widget::show() {
+    visible_ = true;
+}
+
+void Widget::hide() {
+    visible_ = false;
+}
+
+}
@@ -0,0 +1,7 @@
+namespace ui {
+
+void Widget::hide() {
+    visible_ = false;
+}
+
+}
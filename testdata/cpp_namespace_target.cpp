@@ -0,0 +1,7 @@
+namespace ui {
+
+void Widget::show() {
+    visible_ = true;
+}
+
+}
@@ -0,0 +1,13 @@
+#include "widget.hpp"
+
+namespace ui {
+
+void Widget::draw(int x) {
+    paint(x, y_);
+}
+
+void Widget::draw(int x, int y) {
+    paint(x, y);
+}
+
+}
@@ -0,0 +1,9 @@
+#include "widget.hpp"
+
+namespace ui {
+
+void Widget::draw(int x) {
+    paint(x, y_);
+}
+
+}
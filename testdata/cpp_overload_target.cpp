@@ -0,0 +1,13 @@
+#include "widget.hpp"
+
+namespace ui {
+
+void Widget::draw(int x) {
+    paint(x, 0);
+}
+
+void Widget::draw(int x, int y) {
+    paint(x, y);
+}
+
+}
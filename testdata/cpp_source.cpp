@@ -0,0 +1,36 @@
+#include "widget.hpp"
+
+namespace ui {
+
+/// Creates a widget with a title.
+Widget::Widget(int width, std::string title) : width_(width), title_{std::move(title)} {
+    init();
+}
+
+template <typename T>
+T Widget::clamp(T value, T lo, T hi) const noexcept {
+    return value < lo ? lo : (value > hi ? hi : value);
+}
+
+int Widget::resize(int w) {
+    width_ = w;
+    return width_;
+}
+
+int Widget::resize(int w, int h) {
+    width_ = w;
+    height_ = h;
+    return width_ * height_;
+}
+
+class Layout {
+public:
+    explicit Layout(int gap) : gap_(gap) {}
+    int gap() const { return gap_; }
+    void setGap(int gap) { gap_ = gap; }
+
+private:
+    int gap_;
+};
+
+}  // namespace ui
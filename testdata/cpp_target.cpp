@@ -0,0 +1,34 @@
+#include "widget.hpp"
+
+namespace ui {
+
+Widget::Widget(int width) : width_(width) {
+    init();
+}
+
+template <typename T>
+T Widget::clamp(T value, T lo, T hi) const {
+    return value < lo ? lo : value;
+}
+
+int Widget::resize(int w) {
+    width_ = w;
+    return 0;
+}
+
+int Widget::resize(int w, int h) {
+    width_ = w;
+    height_ = h;
+    return 0;
+}
+
+class Layout {
+public:
+    explicit Layout(int gap) : gap_(gap) {}
+    int gap() const { return gap_; }
+
+private:
+    int gap_;
+};
+
+}  // namespace ui
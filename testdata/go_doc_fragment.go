@@ -0,0 +1,5 @@
+// Documented prints a greeting.
+// Updated doc from fragment.
+func Documented() {
+	fmt.Println("Documented body from fragment")
+}
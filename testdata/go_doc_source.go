@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// Documented prints a greeting.
+// Updated doc from source.
+//
+//go:noinline
+func Documented() {
+	fmt.Println("Documented body from source")
+}
+
+func Undocumented() {
+	fmt.Println("Undocumented body from source")
+}
+
+//export ExportedHelper
+//nolint:unused
+func ExportedHelper() {
+	fmt.Println("ExportedHelper body from source")
+}
@@ -0,0 +1,18 @@
+package main
+
+import "fmt"
+
+// Documented prints a greeting.
+// Stale doc from target.
+func Documented() {
+	fmt.Println("Documented body from target")
+}
+
+// Undocumented keeps this doc from target.
+func Undocumented() {
+	fmt.Println("Undocumented body from target")
+}
+
+func ExportedHelper() {
+	fmt.Println("ExportedHelper body from target")
+}
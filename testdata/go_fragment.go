@@ -0,0 +1,9 @@
+// A clipboard fragment without a package clause.
+
+func FragmentFirst(m map[string]interface{}) int {
+	return len(m)
+}
+
+func (s *Service) FragmentMethod() {
+	fmt.Println("fragment method")
+}
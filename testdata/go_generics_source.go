@@ -0,0 +1,28 @@
+package main
+
+// Map applies f to every element.
+func Map[T any, U any](items []T, f func(T) U) []U {
+	out := make([]U, 0, len(items))
+	for _, item := range items {
+		out = append(out, f(item))
+	}
+	return out // Map from source
+}
+
+func Filter[S ~[]E, E interface{ comparable }](items S, keep func(E) bool) S {
+	var out S
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out // Filter from source
+}
+
+func (l *List[T]) Push(v T) {
+	l.items = append(l.items, v) // Push from source
+}
+
+func (p Pair[K, V]) Key() K {
+	return p.key // Key from source
+}
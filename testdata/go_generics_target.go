@@ -0,0 +1,22 @@
+package main
+
+type List[E any] struct {
+	items []E
+}
+
+type Pair[K comparable, V any] struct {
+	key K
+	val V
+}
+
+func Map[T, U any](items []T, f func(T) U) []U {
+	return nil // Map from target
+}
+
+func (l *List[E]) Push(v E) {
+	// Push from target
+}
+
+func (pair Pair[A, B]) Key() A {
+	return pair.key // Key from target
+}
@@ -0,0 +1,22 @@
+// A clipboard fragment that go/parser rejects (note the placeholder below),
+// so only the lightweight brace balancer can extract it.
+
+func QueryTemplate() string {
+	return `SELECT '{"a": 1}' FROM t WHERE x = "}" -- lone " here`
+}
+
+func RuneQuote(r rune) bool {
+	// don't trip on the quote in this comment
+	return r == '"' || r == '{' || r == '\''
+}
+
+/* block comment with "quote and { brace */
+func JSONBody() []byte {
+	return []byte(`{"items": [{"id": 1}, {"id": 2}]`)
+}
+
+func Usage() string {
+	return "func NotAFunction() {"
+}
+
+...
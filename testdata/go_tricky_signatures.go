@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+// The regex header search used to take the first "{" as the body start.
+func AcceptsEmptyInterface(x interface{}) error {
+	return fmt.Errorf("got %v", x)
+}
+
+func ReturnsAnonymousStruct() struct{ a int } {
+	return struct{ a int }{a: 1}
+}
+
+func MentionsFuncInString() string {
+	return "func Foo() { this is not a function }"
+}
+
+func TakesCallback(cb func(interface{}) bool) bool {
+	return cb(struct{}{})
+}
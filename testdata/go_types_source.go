@@ -0,0 +1,22 @@
+package main
+
+// Request is the request payload.
+type Request struct {
+	ID   string `json:"id"`
+	Mode int    `json:"mode"` // Mode from source
+}
+
+type (
+	// Handler handles requests.
+	Handler interface {
+		Handle(r Request) error // Handler from source
+	}
+
+	ID = string // alias from source
+)
+
+type NewSourceOnlyType struct{ Value int }
+
+func UseRequest(r Request) {
+	_ = r // UseRequest from source
+}
@@ -0,0 +1,20 @@
+package main
+
+type (
+	// Request is the stale request payload.
+	Request struct {
+		ID string `json:"id"`
+	}
+
+	Keep int
+)
+
+type Handler interface {
+	Handle(r Request) // Handler from target
+}
+
+type ID = int // alias from target
+
+func UseRequest(r Request) {
+	_ = r // UseRequest from target
+}
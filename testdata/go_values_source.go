@@ -0,0 +1,27 @@
+package main
+
+import "errors"
+
+var ErrNotFound = errors.New("not found from source")
+
+var ErrNewFromSource = errors.New("new error from source")
+
+// Prices for premium sessions: duration_in_seconds -> price_in_cents
+var premiumSessionPrices = map[int]int64{
+	300: 1800,
+	600: 3200,
+	900: 4500, // added in source
+}
+
+const (
+	StateIdle State = iota
+	StateRunning
+	StateStopped // new from source
+)
+
+const (
+	fiveMinutes = 300
+	tenMinutes  = 600 // new group from source
+)
+
+var _ fmt.Stringer = (*Thing)(nil)
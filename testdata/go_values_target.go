@@ -0,0 +1,20 @@
+package main
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("not found from target")
+	ErrOther    = errors.New("other")
+)
+
+var premiumSessionPrices = map[int]int64{
+	300: 1800,
+	600: 3200,
+}
+
+type State int
+
+const (
+	StateIdle State = iota
+	StateRunning
+)
@@ -0,0 +1,64 @@
+package com.example.users;
+
+import java.util.List;
+
+/**
+ * Stores users in the database.
+ */
+@Repository
+public class UserRepository {
+    private static final String INSERT = "INSERT INTO users (name) VALUES (?)";
+
+    private final Database db;
+
+    @Inject
+    public UserRepository(Database db) {
+        this.db = db;
+    }
+
+    /** Saves a single user. */
+    @Transactional
+    public void save(User user) {
+        db.execute(INSERT, user.getName());
+        onSave.run();
+    }
+
+    @Transactional
+    public void save(List<User> users) {
+        for (User user : users) {
+            save(user);
+        }
+    }
+
+    public void delete(User user) {
+        db.delete(user);
+    }
+
+    public enum Order {
+        NAME, AGE;
+
+        String column() {
+            return key;
+        }
+
+        Order(String key) {
+            this.key = key;
+        }
+    }
+
+    public interface Listener {
+        void onSaved(User user);
+
+        default void onSaved(List<User> users) {
+            users.forEach(this::onSaved);
+        }
+    }
+
+    public <T extends User> T find(Class<T> type, final String... names) throws NotFoundException {
+        return db.query(type, names);
+    }
+
+    public int count(int[] ids, char separator) {
+        return separator == '}' ? 0 : ids.length;
+    }
+}
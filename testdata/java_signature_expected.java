@@ -0,0 +1,11 @@
+public class UserRepository {
+    private final Map<Long, User> active = new HashMap<>();
+
+    public User find(long id, boolean withDeleted) {
+        return withDeleted ? all.get(id) : active.get(id);
+    }
+
+    public void save(User user) {
+        active.put(user.getId(), user);
+    }
+}
@@ -0,0 +1,5 @@
+public class UserRepository {
+    public User find(long id, boolean withDeleted) {
+        return withDeleted ? all.get(id) : active.get(id);
+    }
+}
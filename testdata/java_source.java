@@ -0,0 +1,73 @@
+package com.example.users;
+
+import java.util.List;
+
+/**
+ * Stores users in the database.
+ */
+@Repository
+public class UserRepository {
+    private static final String INSERT = """
+        INSERT INTO users (name) VALUES (?) -- { not a block
+        """;
+
+    private final Runnable onSave = new Runnable() {
+        @Override
+        public void run() {}
+    };
+
+    private final Database db;
+
+    @Inject
+    public UserRepository(Database db) {
+        this.db = db;
+    }
+
+    /** Saves a single user. */
+    @Transactional
+    public void save(User user) {
+        db.execute(INSERT, user.getName());
+        onSave.run();
+    }
+
+    @Transactional
+    public void save(List<User> users) {
+        for (User user : users) {
+            save(user);
+        }
+    }
+
+    public <T extends User> T find(Class<T> type, final String... names) throws NotFoundException {
+        return db.query(type, names);
+    }
+
+    public int count(int[] ids, char separator) {
+        return separator == '}' ? 0 : ids.length;
+    }
+
+    public enum Order {
+        NAME("name") {
+            @Override
+            String column() { return "name"; }
+        },
+        AGE("age");
+
+        private final String key;
+
+        Order(String key) {
+            this.key = key;
+        }
+
+        String column() {
+            return key;
+        }
+    }
+
+    public interface Listener {
+        void onSaved(User user);
+
+        default void onSaved(List<User> users) {
+            users.forEach(this::onSaved);
+        }
+    }
+}
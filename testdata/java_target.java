@@ -0,0 +1,42 @@
+package com.example.users;
+
+import java.util.List;
+
+/**
+ * Stores users in the database.
+ */
+@Repository
+public class UserRepository {
+    private static final String INSERT = "INSERT INTO users (name) VALUES (?)";
+
+    private final Database db;
+
+    public UserRepository(Database db) {
+        this.db = db;
+    }
+
+    /** Saves a single user. */
+    public void save(User user) {
+        db.execute(INSERT, user.getName());
+    }
+
+    public void save(List<User> users) {
+        users.forEach(this::save);
+    }
+
+    public void delete(User user) {
+        db.delete(user);
+    }
+
+    public enum Order {
+        NAME, AGE;
+
+        String column() {
+            return name().toLowerCase();
+        }
+    }
+
+    public interface Listener {
+        void onSaved(User user);
+    }
+}
@@ -0,0 +1,29 @@
+package com.example.users
+
+/** A user id. */
+@JvmInline
+value class UserId(val value: String) {
+    fun masked(): String = value.take(2) + "***"
+}
+
+class UserRepository(private val db: Database) {
+    private val template = "INSERT INTO ${db.table("users") { "}" }} VALUES (?)"
+
+    override fun save(user: User) {
+        db.insert(user)
+    }
+
+    fun save(users: List<User>) = users
+        .forEach { save(it) }
+
+    suspend fun <T : User> find(type: Class<T>, vararg names: String): T? =
+        db.query(type, *names)
+
+    companion object {
+        fun create(): UserRepository = UserRepository(Database("jdbc:h2:mem"))
+    }
+}
+
+fun String.toUserId(): UserId = UserId(this)
+
+fun List<User>.names(separator: Char = ','): String = joinToString("$separator") { it.name }
@@ -0,0 +1,19 @@
+package com.example.users
+
+/** A user id. */
+@JvmInline
+value class UserId(val value: String)
+
+class UserRepository(private val db: Database) {
+    override fun save(user: User) {
+        db.insert(user, replace = false)
+    }
+
+    fun save(users: List<User>) = users.forEach(::save)
+
+    companion object {
+        fun create(): UserRepository = UserRepository(Database("jdbc:h2:file"))
+    }
+}
+
+fun String.toUserId(): UserId = UserId(trim())
@@ -0,0 +1,41 @@
+import numpy as np
+
+
+# Normalizes a batch to zero mean.
+def normalize(batch, eps=1e-6):
+  mean = batch.mean(axis=0)
+  return (batch - mean) / (batch.std(axis=0) + eps)
+
+
+class Model:
+  """Wraps the classifier."""
+
+  @torch.no_grad()
+  def predict(self, x):
+    logits = self.net(x)
+    return logits.argmax(dim=1)
+
+  async def warmup(self,
+                   steps: int = 3) -> None:
+    for _ in range(steps):
+      await self.predict_async(EXAMPLE)
+
+  def describe(self):
+    return """
+def not_a_function():
+    pass
+"""
+
+  class Config:
+    def defaults(self):
+      return {"lr": 1e-3, "batch": 64}
+
+
+def train(model, data):
+  for batch in data:
+    model.step(normalize(batch))
+
+
+class Scheduler:
+  def step(self):
+    self.epoch += 1
@@ -0,0 +1,57 @@
+use std::fmt;
+
+/// A parsed token with a borrowed slice of the input.
+#[derive(Debug, Clone)]
+pub struct Token<'a> {
+    pub text: &'a str,
+    pub line: usize,
+}
+
+pub const BRACE: char = '{';
+
+impl<'a> Token<'a> {
+    /// Creates a token.
+    pub fn new(text: &'a str, line: usize) -> Self {
+        Token { text, line }
+    }
+
+    pub fn is_open(&self) -> bool {
+        self.text.starts_with(BRACE) || self.text == r#"{"#
+    }
+
+    #[inline]
+    pub fn len(&self) -> usize {
+        self.text.len()
+    }
+}
+
+impl<'a> fmt::Display for Token<'a> {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, r#"Token("{}") at {}"#, self.text, self.line)
+    }
+}
+
+impl From<&'static str> for Token<'static> {
+    fn from(text: &'static str) -> Self {
+        Token::new(text, 0)
+    }
+}
+
+pub trait Visitor {
+    fn visit(&mut self, token: &Token<'_>);
+
+    fn done(&mut self) {}
+}
+
+mod util {
+    pub fn trim<'a>(s: &'a str) -> &'a str {
+        s.trim_matches(|c| c == '}' || c == ' ')
+    }
+}
+
+pub async fn tokenize<'a>(input: &'a str) -> Vec<Token<'a>>
+where
+    'a: 'static,
+{
+    input.split(' ').enumerate().map(|(i, t)| Token::new(t, i)).collect()
+}
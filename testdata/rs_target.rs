@@ -0,0 +1,32 @@
+use std::fmt;
+
+/// A parsed token with a borrowed slice of the input.
+#[derive(Debug)]
+pub struct Token<'a> {
+    pub text: &'a str,
+}
+
+pub const BRACE: char = '{';
+
+impl<'a> Token<'a> {
+    /// Creates a token.
+    pub fn new(text: &'a str) -> Self {
+        Token { text }
+    }
+
+    pub fn is_open(&self) -> bool {
+        self.text == "{"
+    }
+}
+
+impl<'a> fmt::Display for Token<'a> {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.text)
+    }
+}
+
+pub trait Visitor {
+    fn visit(&mut self, token: &Token<'_>);
+}
+
+mod util {}
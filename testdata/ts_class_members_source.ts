@@ -0,0 +1,34 @@
+export class Counter {
+    private count = 0;
+
+    increment(): void {
+        this.count++;
+    }
+
+    /** Resets the counter. */
+    reset(): void {
+        this.count = 0;
+    }
+}
+
+class Empty {
+  describe(): string {
+    return "no longer empty";
+  }
+}
+
+class Settings {
+    theme = "dark";
+
+    toggle(): void {
+        this.theme = this.theme === "dark" ? "light" : "dark";
+    }
+}
+
+export class Logger {
+    constructor(private readonly prefix: string) {}
+
+    log(message: string): void {
+        console.log(`${this.prefix} ${message}`);
+    }
+}
@@ -0,0 +1,17 @@
+export class Counter {
+    private count = 0;
+
+    increment(): void {
+        this.count += 1;
+    }
+}
+
+class Empty {}
+
+class Settings {
+    theme = "dark";
+}
+
+export function main() {
+    new Counter().increment();
+}
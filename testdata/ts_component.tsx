@@ -0,0 +1,47 @@
+import React from "react";
+
+export function List({ items }: { items: string[] }) {
+    if (items.length === 0) {
+        return <p className="empty">Nothing here } yet, isn't it?</p>;
+    }
+    return (
+        <ul style={{ margin: 0 }}>
+            {items.map((item) => (
+                <li key={item} onClick={() => { select(item); }}>
+                    {item} {"}"}
+                </li>
+            ))}
+            <>
+                {/* fragment { */}
+            </>
+            <p>Don't {items.map((item) => (
+                <b>{item}</b>
+            ))}</p>
+        </ul>
+    );
+}
+
+export const identity = <T,>(value: T): T => {
+    return value;
+};
+
+export const Badge = (props: { label: string }) => {
+    return props.label.length > 3 ? <b>{props.label}</b> : <i>{"{"}</i>;
+};
+
+export const Button: React.FC<ButtonProps & { onClick?: () => void }> = ({ label, size = "md", ...rest }) => {
+    return <button className={`btn-${size}`} {...rest}>{label}</button>;
+};
+
+export const useSelection = <T extends { id: string },>(items: T[], initial: T | null = null): Selection<T> => {
+    const [selected, setSelected] = React.useState<T | null>(initial);
+    return { selected, select: (item: T) => setSelected(item) };
+};
+
+const fallback: (error: Error) => JSX.Element = ({ message }) => <pre>{message}</pre>;
+
+const limit: number = 10;
+
+function last() {
+    return <Footer />;
+}
@@ -0,0 +1,28 @@
+export class Header {
+    render(): string {
+        return "<header>";
+    }
+}
+
+export class Footer {
+    render(): string {
+        return "<footer> from source";
+    }
+}
+
+export const api = {
+    users: {
+        list() {
+            return fetch("/users?source");
+        },
+    },
+    ping: async () => {
+        return "pong from source";
+    },
+};
+
+namespace Util {
+    export function format(value: number): string {
+        return `${value} from source`;
+    }
+}
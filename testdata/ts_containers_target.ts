@@ -0,0 +1,32 @@
+export class Header {
+    render(): string {
+        return "<header> from target";
+    }
+}
+
+export class Footer {
+    render(): string {
+        return "<footer>";
+    }
+}
+
+export const api = {
+    users: {
+        list() {
+            return fetch("/users");
+        },
+    },
+    ping: async () => {
+        return "pong";
+    },
+};
+
+function format(value: number): string {
+    return `${value} top-level from target`;
+}
+
+namespace Util {
+    export function format(value: number): string {
+        return `${value}`;
+    }
+}
@@ -0,0 +1,19 @@
+/** Page shows the dashboard. */
+export default function Dashboard() {
+    return render("dashboard");
+}
+
+export const handler = async function (req: Request) {
+    return respond(req, 201);
+};
+
+export const add = (a: number, b: number): number => a + b + 0;
+
+export const twice = async x => {
+    return [x, x];
+};
+
+const label = (item) =>
+    item.title.toUpperCase()
+
+export const double = x => x * 2;
@@ -0,0 +1,19 @@
+/** Home shows the landing page. */
+export default function Home() {
+    return render("home");
+}
+
+export const handler = function (req: Request) {
+    return respond(req, 200);
+};
+
+export const add = (a: number, b: number): number => a + b;
+
+export const twice = async x => {
+    return [x];
+};
+
+const label = (item) =>
+    item.title
+
+const limit = 10;
@@ -0,0 +1,44 @@
+export abstract class Shape {
+    abstract area(): number;
+
+    /** Scales the shape from source. */
+    scale(factor: number): void;
+    scale(x: number, y: number): void;
+    scale(x: number, y?: number): void {
+        this.resize(x, y ?? x);
+    }
+
+    get name(): string {
+        return this.#label + " from source";
+    }
+
+    set name(value: string) {
+        this.#label = value;
+    }
+
+    *points(): Generator<number> {
+        yield 1;
+    }
+
+    #describe(): string {
+        return `private from source`;
+    }
+}
+
+@Controller("items")
+export class ItemsController {
+    /**
+     * Returns one item.
+     */
+    @Get(":id")
+    @UseGuards(AuthGuard)
+    async findOne(@Param("id") id: string): Promise<Item> {
+        return this.items.get(id, "source");
+    }
+}
+
+export function parse(value: string): number;
+export function parse(value: number): number;
+export function parse(value: string | number): number {
+    return Number(value) + 1;
+}
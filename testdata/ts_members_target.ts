@@ -0,0 +1,36 @@
+export abstract class Shape {
+    abstract area(): number;
+
+    scale(factor: number): void;
+    scale(x: number, y?: number): void {
+        this.resize(x, y);
+    }
+
+    get name(): string {
+        return this.#label;
+    }
+
+    set name(value: string) {
+        this.#label = value.trim();
+    }
+
+    *points(): Generator<number> {
+        yield 0;
+    }
+
+    #describe(): string {
+        return `private`;
+    }
+}
+
+@Controller("items")
+export class ItemsController {
+    @Get(":id")
+    findOne(id: string): Item {
+        return this.items.get(id);
+    }
+}
+
+export function parse(value: string | number): number {
+    return Number(value);
+}
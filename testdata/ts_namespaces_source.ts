@@ -0,0 +1,28 @@
+namespace Api {
+    export function get(path: string): Promise<Response> {
+        return fetch(`/api/v2/${path}`);
+    }
+
+    /** post sends a JSON body. */
+    export function post(path: string, body: unknown): Promise<Response> {
+        return fetch(`/api/v2/${path}`, { method: "POST", body: JSON.stringify(body) });
+    }
+}
+
+declare module "express" {
+    interface Request {
+        user?: User;
+    }
+
+    export function session(): Handler;
+}
+
+declare global {
+    interface Window {
+        analytics: Analytics;
+    }
+}
+
+namespace Cache.Keys {
+    export const user = (id: string) => `user:${id}`;
+}
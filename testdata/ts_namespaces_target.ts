@@ -0,0 +1,15 @@
+namespace Api {
+    export function get(path: string): Promise<Response> {
+        return fetch(`/api/${path}`);
+    }
+}
+
+declare module 'express' {
+    interface Request {
+        user?: unknown;
+    }
+}
+
+export function get(): string {
+    return "top-level";
+}
@@ -0,0 +1,36 @@
+export const api = { a() { return 1; },
+
+    b() { return 3; },
+
+    c: () => 3
+};
+
+export const handlers = {
+    load() {
+        return "load from source";
+    },
+    format: (value: string) => value.trim().toLowerCase(),
+    timeout: 30,
+
+    save: async (id: string) => {
+        return `saved ${id}`;
+    },
+
+    users: {
+        list() {
+            return "users";
+        },
+    },
+};
+
+const empty = {
+    init() {
+        return true;
+    }
+};
+
+export const routes = {
+    home() {
+        return "/";
+    },
+};
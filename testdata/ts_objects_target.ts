@@ -0,0 +1,11 @@
+export const api = { a() { return 2; } };
+
+export const handlers = {
+    load() {
+        return "load from target";
+    },
+    format: (value: string) => value.trim(),
+    timeout: 30,
+};
+
+const empty = {};
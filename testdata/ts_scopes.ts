@@ -0,0 +1,63 @@
+// ts_scopes.ts
+
+const pattern = /function fake() {/g;
+const brace = "}";
+
+export function outer(items: string[]): { count: number } {
+    if (items.length > 0) {
+        return { count: items.length };
+    }
+    function inner() {
+        return `}${brace}`;
+    }
+    const helper = (x: number) => {
+        return x / 2;
+    };
+    return { count: inner().length + helper(4) };
+}
+
+/*
+function blockCommented() {
+}
+*/
+
+if (pattern.test("x")) {
+    console.log("not a declaration");
+}
+
+for (const item of [1, 2]) {
+    console.log(item);
+}
+
+export const typed = async <T>(value: T): Promise<T> => {
+    const re = /[}{]/;
+    return value;
+};
+
+export class Service<T extends { id: string }> extends Base<T> {
+    private cache = new Map<string, T>();
+
+    constructor(private readonly name: string) {
+        super();
+    }
+
+    async load(id: string): Promise<T | undefined> {
+        while (!this.cache.has(id)) {
+            await this.fetch(id);
+        }
+        return this.cache.get(id);
+    }
+
+    static create(): Service<{ id: string }> {
+        return new Service("default");
+    }
+}
+
+switch (brace) {
+    case "}":
+        break;
+}
+
+function last() {
+    return 42;
+}
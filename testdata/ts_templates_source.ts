@@ -0,0 +1,17 @@
+export function greet(user: { name: string }, count: number): string {
+    return `Hello ${user.name}, you have ${count} items`;
+}
+
+export const Title = styled.div`
+    color: ${(props) => (props.active ? "red" : "blue")};
+    &:hover { color: black; }
+`;
+
+export function nested(items: string[]): string {
+    const list = `${items.map((item) => `<li>${item.replace(/}/g, "")}</li>`).join("")}`;
+    return `<ul>${list}</ul> {from source`;
+}
+
+function after() {
+    return `}`;
+}
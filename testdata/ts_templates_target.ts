@@ -0,0 +1,16 @@
+export function greet(user: { name: string }, count: number): string {
+    return `Hi ${user.name}`;
+}
+
+export const Title = styled.div`
+    color: ${(props) => (props.active ? "red" : "blue")};
+    &:hover { color: black; }
+`;
+
+export function nested(items: string[]): string {
+    return `<ul>${items.map((item) => `<li>${item}</li>`).join("")}</ul>`;
+}
+
+function after() {
+    return `{`;
+}
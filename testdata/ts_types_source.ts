@@ -0,0 +1,23 @@
+/** Props of the list from source. */
+export interface ListProps<T extends { id: string }> {
+    items: T[];
+    onSelect(item: T): void;
+}
+
+export type State =
+    | { status: "idle" }
+    | { status: "loading"; since: number };
+
+export const enum Direction {
+    Up = "UP",
+    Down = "DOWN",
+    Left = "LEFT",
+}
+
+declare const VERSION: string;
+
+type Handler = (event: Event) => void;
+
+export function render(props: ListProps<{ id: string }>): State {
+    return { status: "idle" };
+}
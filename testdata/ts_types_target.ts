@@ -0,0 +1,16 @@
+export interface ListProps<T extends { id: string }> {
+    items: T[];
+}
+
+export type State = { status: "idle" };
+
+export const enum Direction {
+    Up = "UP",
+    Down = "DOWN",
+}
+
+declare const VERSION: number;
+
+export function render(props: ListProps<{ id: string }>): State {
+    return { status: "idle" };
+}
@@ -0,0 +1,103 @@
+package main
+
+// tokenClass is what the shared bracket helpers need to know of a token.
+type tokenClass int
+
+const (
+	classIdent tokenClass = iota // identifiers and keywords
+	classPunct
+	classOther // literals and other tokens that never match a keyword or bracket
+)
+
+// indexedToken is a token of one of the language tokenizers.
+type indexedToken interface {
+	tokenText() string
+	tokenClass() tokenClass
+}
+
+// tokenIndex is the scaffolding the TypeScript, Rust and C-like parsers share:
+// the text and class of each token and the index of each bracket's partner.
+type tokenIndex struct {
+	texts   []string
+	classes []tokenClass
+	match   []int // index of the matching bracket for (, [, { and their closers, or -1
+}
+
+// newTokenIndex indexes tokens and matches their brackets. A closer drops the
+// unclosed openers above its partner and a closer without one is ignored, so
+// that one stray bracket does not shift the rest.
+func newTokenIndex[T indexedToken](tokens []T) tokenIndex {
+	x := tokenIndex{
+		texts:   make([]string, len(tokens)),
+		classes: make([]tokenClass, len(tokens)),
+		match:   make([]int, len(tokens)),
+	}
+	var stack []int
+	for i, tok := range tokens {
+		x.texts[i], x.classes[i], x.match[i] = tok.tokenText(), tok.tokenClass(), -1
+		if x.classes[i] != classPunct {
+			continue
+		}
+		switch x.texts[i] {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			open := map[string]string{")": "(", "]": "[", "}": "{"}[x.texts[i]]
+			for n := len(stack) - 1; n >= 0; n-- {
+				if x.texts[stack[n]] == open {
+					x.match[i] = stack[n]
+					x.match[stack[n]] = i
+					stack = stack[:n]
+					break
+				}
+			}
+		}
+	}
+	return x
+}
+
+// is tells whether the token at i is the identifier, keyword or punctuation
+// text; literals never match.
+func (x *tokenIndex) is(i int, text string) bool {
+	return i >= 0 && i < len(x.texts) && x.texts[i] == text && x.classes[i] != classOther
+}
+
+func (x *tokenIndex) isIdent(i int) bool {
+	return i >= 0 && i < len(x.texts) && x.classes[i] == classIdent
+}
+
+func (x *tokenIndex) isOpen(i int) bool {
+	return x.is(i, "(") || x.is(i, "[") || x.is(i, "{")
+}
+
+// closing returns the index of the bracket matching the opener at i, or the
+// last index before end when it is unbalanced.
+func (x *tokenIndex) closing(i, end int) int {
+	if m := x.match[i]; m > i && m < end {
+		return m
+	}
+	return end - 1
+}
+
+// skipAngles returns the index just past the <...> group starting at i, or
+// the index of a ";" that ends it early. The ">" of "->" and "=>" does not
+// close it.
+func (x *tokenIndex) skipAngles(i, end int) int {
+	depth := 0
+	for j := i; j < end; j++ {
+		switch {
+		case x.isOpen(j):
+			j = x.closing(j, end)
+		case x.is(j, "<"):
+			depth++
+		case x.is(j, ">") && !x.is(j-1, "-") && !x.is(j-1, "="):
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		case x.is(j, ";"):
+			return j
+		}
+	}
+	return end
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestTokenIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		open    int // index of the token whose partner is checked
+		partner int
+	}{
+		{name: "Вложенные скобки", content: "f(a[1], {b})", open: 1, partner: 10},
+		{name: "Лишняя закрывающая скобка пропускается", content: "{ ) }", open: 0, partner: 2},
+		{name: "Незакрытая скобка не сдвигает остальные", content: "{ ( }", open: 0, partner: 2},
+		{name: "Скобки в строках не учитываются", content: `g("(", ')')`, open: 1, partner: 5},
+	}
+
+	syntax := &cLikeSyntax{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := newTokenIndex(syntax.tokenize(tt.content))
+			if got := x.match[tt.open]; got != tt.partner {
+				t.Errorf("Ожидалась парная скобка %d, получено %d", tt.partner, got)
+			}
+		})
+	}
+
+	t.Run("Угловые скобки", func(t *testing.T) {
+		x := newTokenIndex(syntax.tokenize("Map<K, List<V>> f; Fn<A -> B> g;"))
+		if end := x.skipAngles(1, len(x.texts)); !x.is(end, "f") {
+			t.Errorf("skipAngles остановился на %q вместо f", x.texts[end])
+		}
+		if end := x.skipAngles(12, len(x.texts)); !x.is(end, "g") {
+			t.Errorf("skipAngles остановился на %q вместо g", x.texts[end])
+		}
+	})
+}
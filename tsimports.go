@@ -0,0 +1,351 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+// tsImportSpec is one binding of an ES import: a default import, a namespace
+// import or a named specifier.
+type tsImportSpec struct {
+	text  string // as written, e.g. "useMemo", "b as c" or "type Props"
+	local string // name the binding introduces in the file
+	end   int    // offset just past text in the file
+}
+
+// tsImportDecl is one import declaration of a TypeScript or JavaScript file.
+type tsImportDecl struct {
+	module      string // the quoted module specifier as written
+	typeOnly    bool   // "import type ..."
+	defaultSpec *tsImportSpec
+	namespace   *tsImportSpec // "* as name"
+	named       []tsImportSpec
+	semicolon   bool
+	start, end  int
+	braceOpen   int // offset of "{", or -1 without named specifiers
+	namedEnd    int // just past the last named specifier
+	namedComma  int // just past a "," after the last named specifier, or -1
+}
+
+// parseTSImports returns the top-level import declarations of content.
+// Side-effect imports and "import x = require(...)" are skipped.
+func parseTSImports(content string, jsx bool) []tsImportDecl {
+	p := newTSParser(content, jsx)
+
+	var decls []tsImportDecl
+	for i := 0; i < len(p.tokens); {
+		if p.is(i, "import") {
+			if decl, next := p.parseImport(i); next > i {
+				decls = append(decls, decl)
+				i = next
+				continue
+			}
+		}
+		i = p.skipStatement(i, len(p.tokens))
+	}
+	return decls
+}
+
+// parseImport reads the import declaration whose keyword is at i and returns
+// it with the index after it, or with i when tokens[i:] is not one.
+func (p *tsParser) parseImport(i int) (tsImportDecl, int) {
+	end := len(p.tokens)
+	decl := tsImportDecl{start: p.tokens[i].start, braceOpen: -1, namedComma: -1}
+	spec := func(from, to int) tsImportSpec {
+		return tsImportSpec{text: p.content[p.tokens[from].start:p.tokens[to].end], local: p.tokens[to].text, end: p.tokens[to].end}
+	}
+
+	k := i + 1
+	if p.is(k, "type") && !p.is(k+1, "from") && !p.is(k+1, ",") && !p.is(k+1, "=") {
+		decl.typeOnly = true
+		k++
+	}
+	if p.isIdent(k) && !p.is(k, "from") {
+		s := spec(k, k)
+		decl.defaultSpec = &s
+		k++
+		if p.is(k, ",") {
+			k++
+		}
+	}
+	if p.is(k, "*") && p.is(k+1, "as") && p.isIdent(k+2) {
+		s := spec(k, k+2)
+		decl.namespace = &s
+		k += 3
+	}
+	if p.is(k, "{") {
+		closeIdx := p.closing(k, end)
+		decl.braceOpen = p.tokens[k].start
+		decl.namedEnd = p.tokens[k].end
+		first := k + 1
+		for j := k + 1; j <= closeIdx; j++ {
+			if !p.is(j, ",") && j != closeIdx {
+				continue
+			}
+			if j > first && p.isIdent(j-1) {
+				decl.named = append(decl.named, spec(first, j-1))
+				decl.namedEnd = p.tokens[j-1].end
+				decl.namedComma = -1
+				if j != closeIdx {
+					decl.namedComma = p.tokens[j].end
+				}
+			}
+			first = j + 1
+		}
+		k = closeIdx + 1
+	}
+	if !p.is(k, "from") || k+1 >= end || p.tokens[k+1].kind != tsString {
+		return decl, i
+	}
+	decl.module = p.tokens[k+1].text
+	last := p.withSemicolon(k+1, end)
+	decl.semicolon = last != k+1
+	decl.end = p.tokens[last].end
+	return decl, last + 1
+}
+
+// moduleName returns the module specifier without its quotes.
+func (d tsImportDecl) moduleName() string {
+	return d.module[1 : len(d.module)-1]
+}
+
+// bindings returns every binding the declaration introduces.
+func (d tsImportDecl) bindings() []tsImportSpec {
+	var specs []tsImportSpec
+	if d.defaultSpec != nil {
+		specs = append(specs, *d.defaultSpec)
+	}
+	if d.namespace != nil {
+		specs = append(specs, *d.namespace)
+	}
+	return append(specs, d.named...)
+}
+
+// render formats the declaration as a new import statement.
+func (d tsImportDecl) render() string {
+	var head []string
+	if d.defaultSpec != nil {
+		head = append(head, d.defaultSpec.text)
+	}
+	if d.namespace != nil {
+		head = append(head, d.namespace.text)
+	}
+	if len(d.named) > 0 {
+		texts := make([]string, len(d.named))
+		for i, spec := range d.named {
+			texts[i] = spec.text
+		}
+		head = append(head, "{ "+strings.Join(texts, ", ")+" }")
+	}
+	keyword := "import "
+	if d.typeOnly {
+		keyword = "import type "
+	}
+	text := keyword + strings.Join(head, ", ") + " from " + d.module
+	if d.semicolon {
+		text += ";"
+	}
+	return text
+}
+
+// tsJSXNameRegex finds the identifiers inside a JSX element, such as the
+// component names and the names used in its expressions.
+var tsJSXNameRegex = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// tsUsedNames collects the identifiers the texts refer to, leaving out
+// property names after "." and "?.". The ${...} substitutions of template
+// literals are code too.
+func tsUsedNames(jsx bool, texts ...string) map[string]bool {
+	names := make(map[string]bool)
+	for _, text := range texts {
+		tokens := tokenizeTypeScript(text, jsx)
+		for i, tok := range tokens {
+			switch {
+			case tok.kind == tsJSX:
+				for _, name := range tsJSXNameRegex.FindAllString(tok.text, -1) {
+					names[name] = true
+				}
+			case tok.kind == tsTemplate:
+				for name := range tsUsedNames(jsx, tsTemplateSubstitutions(tok.text)...) {
+					names[name] = true
+				}
+			case tok.kind == tsIdent && (i == 0 || tokens[i-1].text != "." && tokens[i-1].text != "?."):
+				names[tok.text] = true
+			}
+		}
+	}
+	return names
+}
+
+// tsTemplateSubstitutions returns the code inside the ${...} substitutions of
+// the template literal text.
+func tsTemplateSubstitutions(text string) []string {
+	var substitutions []string
+	for j := 1; j < len(text); {
+		switch {
+		case text[j] == '\\':
+			j += 2
+		case strings.HasPrefix(text[j:], "${"):
+			end := skipTSBraces(text, j+1, false)
+			if end == -1 {
+				return append(substitutions, text[j+2:])
+			}
+			substitutions = append(substitutions, text[j+2:end-1])
+			j = end
+		default:
+			j++
+		}
+	}
+	return substitutions
+}
+
+// mergeImports adds the import bindings of sourceContent that the
+// synced declarations use and that targetContent lacks. Named specifiers join
+// an existing import of the same module and kind; type-only imports are only
+// merged with type-only ones. Everything else becomes a new import line after
+// the target's last import.
+func (l *typeScriptLanguage) mergeImports(targetContent, sourceContent string, synced []Function) string {
+	texts := make([]string, len(synced))
+	for i, fn := range synced {
+		texts[i] = fn.FullText
+	}
+	used := tsUsedNames(l.jsx, texts...)
+
+	targetImports := parseTSImports(targetContent, l.jsx)
+	taken := make(map[string]string)
+	for _, decl := range targetImports {
+		for _, spec := range decl.bindings() {
+			taken[spec.local] = decl.moduleName()
+		}
+	}
+	for _, decl := range extractTypeScriptDeclarations(targetContent, l.jsx) {
+		if decl.Container == "" {
+			taken[decl.Name] = ""
+		}
+	}
+
+	var edits []textEdit
+	var newLines []string
+	for _, source := range parseTSImports(sourceContent, l.jsx) {
+		missing := tsImportDecl{module: source.module, typeOnly: source.typeOnly, semicolon: source.semicolon}
+		for _, spec := range source.bindings() {
+			if !used[spec.local] {
+				continue
+			}
+			if module, exists := taken[spec.local]; exists {
+				if module != source.moduleName() {
+					log.Printf("Предупреждение: импорт %s из %s не добавлен: имя уже занято", spec.local, source.module)
+				}
+				continue
+			}
+			taken[spec.local] = source.moduleName()
+			log.Printf("Добавлен импорт %s из %s", spec.local, source.module)
+			switch {
+			case source.defaultSpec != nil && spec == *source.defaultSpec:
+				missing.defaultSpec = &spec
+			case source.namespace != nil && spec == *source.namespace:
+				missing.namespace = &spec
+			default:
+				missing.named = append(missing.named, spec)
+			}
+		}
+		if missing.defaultSpec == nil && missing.namespace == nil && len(missing.named) == 0 {
+			continue
+		}
+
+		// Named specifiers and a default import can join an import of the
+		// same module; a namespace import always needs its own line
+		var target *tsImportDecl
+		for i := range targetImports {
+			decl := &targetImports[i]
+			if decl.moduleName() == source.moduleName() && decl.typeOnly == source.typeOnly && decl.namespace == nil {
+				target = decl
+				break
+			}
+		}
+		if target != nil && missing.namespace == nil && (missing.defaultSpec == nil || target.defaultSpec == nil && !target.typeOnly) {
+			edits = append(edits, tsImportEdits(targetContent, *target, missing)...)
+			continue
+		}
+		newLines = append(newLines, missing.render())
+	}
+
+	if len(newLines) > 0 {
+		text := strings.Join(newLines, "\n")
+		switch {
+		case len(targetImports) > 0:
+			last := targetImports[len(targetImports)-1]
+			edits = append(edits, textEdit{start: last.end, end: last.end, text: "\n" + text})
+		case strings.TrimSpace(targetContent) == "":
+			edits = append(edits, textEdit{start: 0, end: 0, text: text + "\n"})
+		default:
+			at := tsImportInsertPos(targetContent, l.jsx)
+			edits = append(edits, textEdit{start: at, end: at, text: text + "\n\n"})
+		}
+	}
+	return applyEdits(targetContent, edits)
+}
+
+// tsImportEdits adds the default import and named specifiers of missing to
+// the existing declaration decl, following its single- or multi-line layout.
+func tsImportEdits(content string, decl tsImportDecl, missing tsImportDecl) []textEdit {
+	var edits []textEdit
+	if missing.defaultSpec != nil {
+		at := decl.braceOpen
+		if at == -1 {
+			return nil
+		}
+		edits = append(edits, textEdit{start: at, end: at, text: missing.defaultSpec.text + ", "})
+	}
+	if len(missing.named) == 0 {
+		return edits
+	}
+
+	texts := make([]string, len(missing.named))
+	for i, spec := range missing.named {
+		texts[i] = spec.text
+	}
+	switch {
+	case decl.braceOpen == -1:
+		// "import React from 'react'" grows a specifier list
+		at := decl.defaultSpec.end
+		edits = append(edits, textEdit{start: at, end: at, text: ", { " + strings.Join(texts, ", ") + " }"})
+	case len(decl.named) == 0:
+		at := decl.braceOpen + 1
+		edits = append(edits, textEdit{start: at, end: at, text: " " + strings.Join(texts, ", ") + " "})
+	case strings.Contains(content[decl.braceOpen:decl.namedEnd], "\n"):
+		lineStart := strings.LastIndexByte(content[:decl.namedEnd], '\n') + 1
+		indent := content[lineStart : lineStart+len(content[lineStart:decl.namedEnd])-len(strings.TrimLeft(content[lineStart:decl.namedEnd], " \t"))]
+		if decl.namedComma != -1 {
+			edits = append(edits, textEdit{start: decl.namedComma, end: decl.namedComma, text: "\n" + indent + strings.Join(texts, ",\n"+indent) + ","})
+		} else {
+			edits = append(edits, textEdit{start: decl.namedEnd, end: decl.namedEnd, text: ",\n" + indent + strings.Join(texts, ",\n"+indent)})
+		}
+	default:
+		edits = append(edits, textEdit{start: decl.namedEnd, end: decl.namedEnd, text: ", " + strings.Join(texts, ", ")})
+	}
+	return edits
+}
+
+// tsImportInsertPos returns where new imports go in a file without any: after
+// leading directives such as "use client", before the comment attached to the
+// first statement.
+func tsImportInsertPos(content string, jsx bool) int {
+	tokens := tokenizeTypeScript(content, jsx)
+	i := 0
+	for i < len(tokens) && tokens[i].kind == tsString && (i+1 == len(tokens) || tokens[i+1].text == ";" || tokens[i+1].newlineBefore) {
+		i++
+		if i < len(tokens) && tokens[i].text == ";" {
+			i++
+		}
+	}
+	if i == len(tokens) {
+		return len(content)
+	}
+	lo := 0
+	if i > 0 {
+		lo = tokens[i-1].end
+	}
+	return lineStartBefore(content, tsLeadingCommentStart(content, lo, tokens[i].start))
+}
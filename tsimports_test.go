@@ -0,0 +1,173 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMergeTypeScriptImports(t *testing.T) {
+	source := `import React, { useMemo, useState } from "react";
+import type { Props, Unused } from "./types";
+import * as api from "./api";
+import { formatDate } from "./utils";
+import { debounce } from "lodash";
+
+export function List(props: Props) {
+    const [items, setItems] = useState<string[]>([]);
+    const sorted = useMemo(() => items.sort(), [items]);
+    api.fetchItems().then(setItems);
+    return formatDate(new Date()) + sorted.join(",");
+}
+`
+
+	tests := []struct {
+		name     string
+		source   string // overrides the shared source
+		target   string
+		expected string
+	}{
+		{
+			name: "named specifiers join existing imports, type-only stays separate",
+			target: `import { useState } from 'react';
+import { type Props } from "./types";
+import { parseDate } from "./utils";
+
+export function List() {
+    return null;
+}
+`,
+			expected: `import { useState, useMemo } from 'react';
+import { type Props } from "./types";
+import { parseDate, formatDate } from "./utils";
+import * as api from "./api";
+
+export function List() {
+    return null;
+}
+`,
+		},
+		{
+			name: "multi-line list and default import",
+			target: `import {
+    useState,
+} from "react";
+import type { Other } from "./types";
+
+export const x = 1;
+`,
+			expected: `import {
+    useState,
+    useMemo,
+} from "react";
+import type { Other, Props } from "./types";
+import * as api from "./api";
+import { formatDate } from "./utils";
+
+export const x = 1;
+`,
+		},
+		{
+			name: "file without imports keeps directive and doc comment in place",
+			target: `"use client";
+
+/** Entry point. */
+export function main() {}
+`,
+			expected: `"use client";
+
+import { useMemo, useState } from "react";
+import type { Props } from "./types";
+import * as api from "./api";
+import { formatDate } from "./utils";
+
+/** Entry point. */
+export function main() {}
+`,
+		},
+		{
+			name: "local declaration with the same name is not shadowed",
+			target: `import { useState, useMemo } from "react";
+import type { Props } from "./types";
+import * as api from "./api";
+
+function formatDate(d: Date): string {
+    return d.toISOString();
+}
+`,
+			expected: `import { useState, useMemo } from "react";
+import type { Props } from "./types";
+import * as api from "./api";
+
+function formatDate(d: Date): string {
+    return d.toISOString();
+}
+`,
+		},
+		{
+			name: "names used only inside template substitutions",
+			source: `import { useMemo, useState } from "react";
+import { css } from "styled-components";
+import { theme } from "./theme";
+
+export function label() {
+    return ` + "`${useMemo(() => 1, [])} items`" + `;
+}
+
+export function themed() {
+    return css` + "`\n        color: ${(p) => theme.primary};\n    `" + `;
+}
+`,
+			target: `import { useState } from 'react';
+
+export function label() {
+    return "";
+}
+`,
+			expected: `import { useState, useMemo } from 'react';
+import { css } from "styled-components";
+import { theme } from "./theme";
+
+export function label() {
+    return "";
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := source
+			if tt.source != "" {
+				source = tt.source
+			}
+			synced, err := langTypeScript.Extract(source)
+			if err != nil {
+				t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+			}
+
+			result := langTypeScript.mergeImports(tt.target, source, synced)
+			if result != tt.expected {
+				t.Errorf("Ожидалось:\n%s\nполучено:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseTSImports(t *testing.T) {
+	content := "import Default, { a, b as c, type T } from 'm'\nimport type X from \"./x\";\nimport './side-effect';\nconst lazy = import('./lazy');\nimport fs = require('fs');\n"
+
+	imports := parseTSImports(content, false)
+	if len(imports) != 2 {
+		t.Fatalf("Ожидалось 2 импорта, получено %d: %+v", len(imports), imports)
+	}
+
+	var locals []string
+	for _, spec := range imports[0].bindings() {
+		locals = append(locals, spec.local)
+	}
+	if got := len(locals); got != 4 || locals[0] != "Default" || locals[2] != "c" || locals[3] != "T" {
+		t.Errorf("Неверные имена импорта: %v", locals)
+	}
+	if imports[0].semicolon || !imports[1].semicolon || !imports[1].typeOnly {
+		t.Errorf("Неверно распознаны ';' или import type: %+v", imports)
+	}
+}
@@ -0,0 +1,1103 @@
+package main
+
+import (
+	"strings"
+)
+
+// tsTokenKind classifies the tokens produced by tokenizeTypeScript.
+type tsTokenKind int
+
+const (
+	tsIdent tsTokenKind = iota
+	tsNumber
+	tsString
+	tsTemplate
+	tsRegex
+	tsJSX
+	tsPunct
+)
+
+// tsToken is one TypeScript token; comments and whitespace are dropped.
+type tsToken struct {
+	kind          tsTokenKind
+	text          string
+	start, end    int
+	newlineBefore bool // a line break separates the token from the previous one
+}
+
+func (t tsToken) tokenText() string { return t.text }
+
+func (t tsToken) tokenClass() tokenClass {
+	switch t.kind {
+	case tsIdent:
+		return classIdent
+	case tsPunct:
+		return classPunct
+	}
+	return classOther
+}
+
+// tsRegexKeywords are the keywords after which a "/" starts a regex literal.
+var tsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+func isTSIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isTSIdentChar(c byte) bool {
+	return isTSIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// tokenizeTypeScript splits content into tokens, skipping strings, template
+// literals, regex literals and comments as single units so that the braces
+// inside them never count. With jsx set, JSX elements are single tokens too.
+func tokenizeTypeScript(content string, jsx bool) []tsToken {
+	var tokens []tsToken
+	newline := false
+	i := 0
+
+	regexAllowed := func() bool {
+		if len(tokens) == 0 {
+			return true
+		}
+		prev := tokens[len(tokens)-1]
+		switch prev.kind {
+		case tsIdent:
+			return tsRegexKeywords[prev.text]
+		case tsPunct:
+			return prev.text != ")" && prev.text != "]" && prev.text != "}"
+		}
+		return false
+	}
+
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\n':
+			newline = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end
+			}
+			continue
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				end = len(content) - i - 2
+			}
+			if strings.Contains(content[i:i+2+end], "\n") {
+				newline = true
+			}
+			i = min(i+2+end+2, len(content))
+			continue
+		}
+
+		start := i
+		kind := tsPunct
+		switch {
+		case isTSIdentStart(c) || c == '#' && i+1 < len(content) && isTSIdentStart(content[i+1]):
+			// "#name" private members are one identifier
+			kind = tsIdent
+			i++
+			for i < len(content) && isTSIdentChar(content[i]) {
+				i++
+			}
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(content) && content[i+1] >= '0' && content[i+1] <= '9':
+			kind = tsNumber
+			for i < len(content) && (isTSIdentChar(content[i]) || content[i] == '.') {
+				i++
+			}
+		case c == '"' || c == '\'':
+			kind = tsString
+			i = skipTSQuoted(content, i)
+		case c == '`':
+			kind = tsTemplate
+			i = skipTSTemplate(content, i)
+		case c == '<' && jsx && regexAllowed():
+			if end := skipJSXElement(content, i); end > i {
+				kind = tsJSX
+				i = end
+			} else {
+				i++
+			}
+		case c == '/' && regexAllowed():
+			if end := skipTSRegex(content, i); end > i {
+				kind = tsRegex
+				i = end
+			} else {
+				i++
+			}
+		case strings.HasPrefix(content[i:], "=>") || strings.HasPrefix(content[i:], "?."):
+			i += 2
+		case strings.HasPrefix(content[i:], "..."):
+			i += 3
+		default:
+			i++
+		}
+
+		tokens = append(tokens, tsToken{kind: kind, text: content[start:i], start: start, end: i, newlineBefore: newline})
+		newline = false
+	}
+	return tokens
+}
+
+// skipTSQuoted returns the index just past the '...' or "..." string at i.
+func skipTSQuoted(content string, i int) int {
+	quote := content[i]
+	j := i + 1
+	for j < len(content) && content[j] != quote && content[j] != '\n' {
+		if content[j] == '\\' {
+			j++
+		}
+		j++
+	}
+	if j < len(content) && content[j] == quote {
+		j++
+	}
+	return min(j, len(content))
+}
+
+// skipTSTemplate returns the index just past the template literal at i. The
+// ${...} substitutions are scanned as code, so braces, strings and nested
+// template literals inside them do not end the literal early.
+func skipTSTemplate(content string, i int) int {
+	j := i + 1
+	for j < len(content) {
+		switch {
+		case content[j] == '\\':
+			j += 2
+		case content[j] == '`':
+			return j + 1
+		case strings.HasPrefix(content[j:], "${"):
+			if j = skipTSBraces(content, j+1, false); j == -1 {
+				return len(content)
+			}
+		default:
+			j++
+		}
+	}
+	return len(content)
+}
+
+// skipTSRegex returns the index just past the regex literal at i, including
+// its flags, or i when no literal ends on the same line.
+func skipTSRegex(content string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '\n':
+			return i
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(content) && isTSIdentChar(content[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+	return i
+}
+
+// skipJSXElement returns the index just past the JSX element or fragment at
+// i, or i when no complete element starts there, e.g. for the "<T,>" type
+// parameters of a generic arrow function.
+func skipJSXElement(content string, i int) int {
+	depth := 0
+	for j := i; j < len(content); {
+		closing := j+1 < len(content) && content[j+1] == '/'
+		k := j + 1
+		if closing {
+			k++
+		}
+		nameStart := k
+		for k < len(content) && (isTSIdentChar(content[k]) || strings.IndexByte(".:-", content[k]) >= 0) {
+			k++
+		}
+		if j == i {
+			name := content[nameStart:k]
+			rest := strings.TrimLeft(content[k:], " \t\r\n")
+			if name == "" && !strings.HasPrefix(rest, ">") || name != "" && !isTSIdentStart(name[0]) ||
+				strings.HasPrefix(rest, ",") || strings.HasPrefix(rest, "extends ") {
+				return i
+			}
+		}
+
+		// Attributes up to the end of the tag
+		for k < len(content) && content[k] != '>' {
+			switch content[k] {
+			case '"', '\'':
+				end := strings.IndexByte(content[k+1:], content[k])
+				if end == -1 {
+					return i
+				}
+				k += end + 2
+			case '{':
+				if k = skipTSBraces(content, k, true); k == -1 {
+					return i
+				}
+			default:
+				k++
+			}
+		}
+		if k >= len(content) {
+			return i
+		}
+		selfClosing := content[k-1] == '/'
+		k++
+		switch {
+		case closing:
+			depth--
+		case !selfClosing:
+			depth++
+		}
+		if depth <= 0 {
+			return k
+		}
+
+		// Children up to the next tag
+		for k < len(content) && content[k] != '<' {
+			if content[k] == '{' {
+				if k = skipTSBraces(content, k, true); k == -1 {
+					return i
+				}
+				continue
+			}
+			k++
+		}
+		j = k
+	}
+	return i
+}
+
+// skipTSBraces returns the index just past the "{...}" group at i, or -1 when
+// it is not closed. It is used for template substitutions and, with jsx set,
+// for expressions inside JSX elements, which may hold JSX elements again.
+func skipTSBraces(content string, i int, jsx bool) int {
+	depth := 0
+	for j := i; j < len(content); {
+		c := content[j]
+		switch {
+		case c == '"' || c == '\'':
+			j = skipTSQuoted(content, j)
+			continue
+		case c == '`':
+			j = skipTSTemplate(content, j)
+			continue
+		case strings.HasPrefix(content[j:], "//"):
+			if end := strings.IndexByte(content[j:], '\n'); end != -1 {
+				j += end
+				continue
+			}
+			return -1
+		case strings.HasPrefix(content[j:], "/*"):
+			end := strings.Index(content[j+2:], "*/")
+			if end == -1 {
+				return -1
+			}
+			j += end + 4
+			continue
+		case c == '/' && isTSExpressionStart(content, j):
+			if end := skipTSRegex(content, j); end > j {
+				j = end
+				continue
+			}
+		case c == '<' && jsx && isTSExpressionStart(content, j):
+			if end := skipJSXElement(content, j); end > j {
+				j = end
+				continue
+			}
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+		j++
+	}
+	return -1
+}
+
+// isTSExpressionStart reports whether i sits where an expression may start,
+// e.g. after "(", "&&", "=>" or "return", so that a "/" there starts a regex
+// literal and a "<" a JSX element.
+func isTSExpressionStart(content string, i int) bool {
+	before := strings.TrimRight(content[:i], " \t\r\n")
+	if before == "" || strings.HasSuffix(before, "return") {
+		return true
+	}
+	return strings.IndexByte("(,{[=:?&|>!;}", before[len(before)-1]) >= 0
+}
+
+// tsParser recognizes declarations in a token stream. It only descends into
+// module, namespace, class and object literal bodies; function bodies and
+// control statements are skipped as balanced groups.
+type tsParser struct {
+	tokenIndex
+	content      string
+	tokens       []tsToken
+	decls        []Function
+	signatureEnd int // last token of the latest declaration without body, or -1
+}
+
+func newTSParser(content string, jsx bool) *tsParser {
+	p := &tsParser{content: content, tokens: tokenizeTypeScript(content, jsx), signatureEnd: -1}
+	p.tokenIndex = newTokenIndex(p.tokens)
+	return p
+}
+
+// extractTypeScriptDeclarations returns the functions, interfaces, type
+// aliases, enums and ambient variables declared at module or namespace scope
+// and the methods of classes and object literals in content. jsx enables JSX
+// elements for .tsx and .jsx files.
+func extractTypeScriptDeclarations(content string, jsx bool) []Function {
+	p := newTSParser(content, jsx)
+	p.parseStatements(0, len(p.tokens), "")
+	return p.decls
+}
+
+// tsTypeOperators are the tokens after which a "{" starts an object type
+// rather than a function body.
+var tsTypeOperators = map[string]bool{":": true, "|": true, "&": true, "<": true, ",": true, "(": true, "=>": true, "=": true, "?": true, "keyof": true, "typeof": true}
+
+// skipTypeAnnotation skips the ": Type" annotation at i, if any, and returns
+// the index of the token after it: the "{" of a body, a ";", the first token of
+// the next line, or with arrow set the "=>" of an arrow function.
+func (p *tsParser) skipTypeAnnotation(i, end int, arrow bool) int {
+	if !p.is(i, ":") {
+		return i
+	}
+	for j := i + 1; j < end; j++ {
+		switch {
+		case p.tokens[j].newlineBefore && (p.isIdent(j) || p.is(j, "@") || p.is(j, "*")) && !tsTypeOperators[p.tokens[j-1].text] && !p.is(j-1, "."):
+			// A signature without body ends at the line break
+			return j
+		case p.is(j, "{"):
+			if !tsTypeOperators[p.tokens[j-1].text] {
+				return j
+			}
+			j = p.closing(j, end)
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j, end)
+		case p.is(j, "<"):
+			j = p.skipAngles(j, end) - 1
+		case p.is(j, ";"):
+			return j
+		case p.is(j, "=>") && arrow && !p.is(j-1, ")"):
+			return j
+		}
+	}
+	return end
+}
+
+// endsStatement reports whether a statement may end between tokens i-1 and i
+// by automatic semicolon insertion.
+func (p *tsParser) endsStatement(i int) bool {
+	if i <= 0 || i >= len(p.tokens) || !p.tokens[i].newlineBefore {
+		return false
+	}
+	prev, next := p.tokens[i-1], p.tokens[i]
+	if next.kind == tsPunct && next.text != "{" && next.text != "[" && next.text != "(" && next.text != "@" && next.text != "*" {
+		return false
+	}
+	switch prev.kind {
+	case tsPunct:
+		return prev.text == ")" || prev.text == "]" || prev.text == "}"
+	case tsIdent:
+		return !tsTypeOperators[prev.text] && prev.text != "extends" && prev.text != "new"
+	}
+	return true
+}
+
+// skipStatement returns the index just past the statement starting at i.
+func (p *tsParser) skipStatement(i, end int) int {
+	for j := i; j < end; j++ {
+		if j > i && p.endsStatement(j) {
+			return j
+		}
+		switch {
+		case p.is(j, ";"):
+			return j + 1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	return end
+}
+
+// withSemicolon extends a declaration ending at token i by a ";" on the same
+// line.
+func (p *tsParser) withSemicolon(i, end int) int {
+	if i+1 < end && p.is(i+1, ";") && !p.tokens[i+1].newlineBefore {
+		return i + 1
+	}
+	return i
+}
+
+// addDecl records the declaration spanning tokens[first:last+1] together with
+// the comment above it. Overload signatures directly followed by another
+// signature or the implementation of the same name grow into one entry.
+func (p *tsParser) addDecl(name, container string, kind DeclKind, first, last int, hasBody bool) {
+	stop := p.tokens[last].end
+	if n := len(p.decls); n > 0 && p.signatureEnd == first-1 && p.decls[n-1].Name == name && p.decls[n-1].Container == container && kind == DeclFunc {
+		prev := &p.decls[n-1]
+		prev.EndPos = stop
+		prev.FullText = p.content[prev.StartPos:stop]
+	} else {
+		lo := 0
+		if first > 0 {
+			lo = p.tokens[first-1].end
+		}
+		start := tsLeadingCommentStart(p.content, lo, p.tokens[first].start)
+		lineStart := strings.LastIndexByte(p.content[:start], '\n') + 1
+		p.decls = append(p.decls, Function{
+			Name:      name,
+			Kind:      kind,
+			Container: container,
+			Doc:       p.content[start:p.tokens[first].start],
+			FullText:  p.content[start:stop],
+			StartPos:  start,
+			EndPos:    stop,
+			Indent:    p.content[lineStart:start],
+		})
+	}
+
+	p.signatureEnd = -1
+	if !hasBody {
+		p.signatureEnd = last
+	}
+}
+
+// tsLeadingCommentStart returns the start of the JSDoc block or "//" lines
+// directly above the line at pos without reaching back past lo, or pos itself
+// when there is none or when pos is not the first token on its line.
+func tsLeadingCommentStart(content string, lo, pos int) int {
+	lineStart := strings.LastIndexByte(content[:pos], '\n') + 1
+	if lineStart < lo || strings.TrimSpace(content[lineStart:pos]) != "" {
+		return pos
+	}
+
+	start := pos
+	for lineStart > lo {
+		prevStart := max(strings.LastIndexByte(content[:lineStart-1], '\n')+1, lo)
+		prevLine := strings.TrimSpace(content[prevStart : lineStart-1])
+		switch {
+		case strings.HasPrefix(prevLine, "//"):
+			start = prevStart + strings.Index(content[prevStart:], "//")
+		case strings.HasSuffix(prevLine, "*/"):
+			open := strings.LastIndex(content[lo:lineStart], "/*")
+			if open == -1 {
+				return start
+			}
+			open += lo
+			prevStart = strings.LastIndexByte(content[:open], '\n') + 1
+			if prevStart < lo || strings.TrimSpace(content[prevStart:open]) != "" {
+				return start
+			}
+			start = open
+		default:
+			return start
+		}
+		lineStart = prevStart
+	}
+	return start
+}
+
+// qualify appends name to the dot-separated container path.
+func qualify(container, name string) string {
+	if container == "" {
+		return name
+	}
+	if name == "" {
+		return container
+	}
+	return container + "." + name
+}
+
+// functionBody expects the generics or parameter list of a function at k and
+// returns the index of the "}" closing its body, or -1 when there is no body.
+// With arrow set a "=>" must separate the signature from the body.
+func (p *tsParser) functionBody(k, end int, arrow bool) int {
+	if last, hasBody := p.signature(k, end, arrow); hasBody {
+		return last
+	}
+	return -1
+}
+
+// signature is functionBody that also accepts overload and abstract
+// signatures: it then returns their ";" or their last token before a line
+// break, and hasBody false.
+func (p *tsParser) signature(k, end int, arrow bool) (last int, hasBody bool) {
+	if p.is(k, "<") {
+		k = p.skipAngles(k, end)
+	}
+	if !p.is(k, "(") {
+		return -1, false
+	}
+	k = p.skipTypeAnnotation(p.closing(k, end)+1, end, arrow)
+	if arrow {
+		if !p.is(k, "=>") {
+			return -1, false
+		}
+		k++
+	}
+	switch {
+	case p.is(k, "{"):
+		return p.closing(k, end), true
+	case arrow:
+		return -1, false
+	case p.is(k, ";"):
+		return k, false
+	}
+	return k - 1, false
+}
+
+// skipDecorators returns the index after the "@name.path(args)" decorators at
+// i.
+func (p *tsParser) skipDecorators(i, end int) int {
+	for p.is(i, "@") && p.isIdent(i+1) {
+		i += 2
+		for p.is(i, ".") && p.isIdent(i+1) {
+			i += 2
+		}
+		if p.is(i, "(") {
+			i = p.closing(i, end) + 1
+		}
+	}
+	return i
+}
+
+// accessorPrefix skips the "get"/"set" keyword of an accessor and the "*" of a
+// generator before the member name at k. Accessors are named "get x" and
+// "set x" so that both halves of a property are synced on their own.
+func (p *tsParser) accessorPrefix(k int) (prefix string, next int) {
+	if (p.is(k, "get") || p.is(k, "set")) && !p.is(k+1, "(") && !p.is(k+1, "<") && !p.is(k+1, ":") &&
+		!p.is(k+1, "=") && !p.is(k+1, ";") && !p.is(k+1, "?") && !p.is(k+1, ",") && !p.is(k+1, "}") {
+		prefix = p.tokens[k].text + " "
+		k++
+	}
+	if p.is(k, "*") {
+		k++
+	}
+	return prefix, k
+}
+
+// memberName returns the name of the class member or object literal key at
+// k and the index after it. Computed keys keep their brackets.
+func (p *tsParser) memberName(k, end int) (name string, next int, ok bool) {
+	if k >= end {
+		return "", k, false
+	}
+	switch tok := p.tokens[k]; {
+	case tok.kind == tsIdent || tok.kind == tsNumber:
+		return tok.text, k + 1, true
+	case tok.kind == tsString && len(tok.text) >= 2:
+		return tok.text[1 : len(tok.text)-1], k + 1, true
+	case p.is(k, "["):
+		closeIdx := p.closing(k, end)
+		return p.content[tok.start:p.tokens[closeIdx].end], closeIdx + 1, true
+	}
+	return "", k, false
+}
+
+// tsStatementModifiers may precede a module-level declaration.
+var tsStatementModifiers = map[string]bool{"export": true, "default": true, "declare": true, "async": true, "abstract": true}
+
+// parseStatements walks the statements in tokens[from:to] at module or
+// namespace scope.
+func (p *tsParser) parseStatements(from, to int, container string) {
+	for i := from; i < to; {
+		j := p.skipDecorators(i, to)
+		declared, exportDefault := false, false
+		for j < to && p.isIdent(j) && tsStatementModifiers[p.tokens[j].text] {
+			declared = declared || p.is(j, "declare")
+			exportDefault = exportDefault || p.is(j, "default")
+			j++
+		}
+		// The default export is keyed "default" whatever its name, so that
+		// renaming the component or page it holds still matches
+		name := ""
+		if exportDefault {
+			name = "default"
+		}
+
+		next := -1
+		switch {
+		case p.is(j, "function"):
+			next = p.parseFunctionDecl(i, j, to, container, name)
+		case p.is(j, "class"):
+			next = p.parseClass(i, j, to, container, name)
+		case p.is(j, "interface") && p.isIdent(j+1):
+			next = p.parseTypeDecl(i, j, to, container, DeclInterface)
+		case p.is(j, "type") && p.isIdent(j+1):
+			next = p.parseTypeDecl(i, j, to, container, DeclType)
+		case p.is(j, "enum") && p.isIdent(j+1):
+			next = p.parseTypeDecl(i, j, to, container, DeclEnum)
+		case p.is(j, "const") && p.is(j+1, "enum") && p.isIdent(j+2):
+			next = p.parseTypeDecl(i, j+1, to, container, DeclEnum)
+		case declared && (p.is(j, "const") || p.is(j, "let") || p.is(j, "var")) && p.isIdent(j+1):
+			next = p.parseAmbientVariable(i, j, to, container)
+		case p.is(j, "const") || p.is(j, "let") || p.is(j, "var"):
+			next = p.parseVariable(i, j, to, container)
+		case p.is(j, "namespace") || p.is(j, "module") || declared && p.is(j, "global"):
+			next = p.parseNamespace(i, j, to, container)
+		case exportDefault && p.is(j, "{"):
+			next = p.parseObjectBlock(i, j, to, container, name)
+		case exportDefault:
+			if last := p.functionExpression(j, to); last != -1 {
+				p.addDecl(name, container, DeclFunc, i, last, true)
+				next = last + 1
+			}
+		}
+		if next > i {
+			i = next
+			continue
+		}
+		i = p.skipStatement(i, to)
+	}
+}
+
+// parseFunctionDecl handles "function name<T>(...): R { ... }" whose keyword
+// is at j and whose modifiers start at first. A non-empty name replaces the
+// declared one and allows an anonymous default export. It returns the index
+// after the declaration, or -1 when tokens[j:] is not one.
+func (p *tsParser) parseFunctionDecl(first, j, end int, container, name string) int {
+	k := j + 1
+	if p.is(k, "*") {
+		k++
+	}
+	if p.isIdent(k) {
+		if name == "" {
+			name = p.tokens[k].text
+		}
+		k++
+	}
+	if name == "" {
+		return -1
+	}
+	last, hasBody := p.signature(k, end, false)
+	if last == -1 {
+		return -1
+	}
+	p.addDecl(name, container, DeclFunc, first, last, hasBody)
+	return last + 1
+}
+
+// parseTypeDecl handles an interface, type alias or enum whose keyword is at j
+// and whose modifiers start at first.
+func (p *tsParser) parseTypeDecl(first, j, end int, container string, kind DeclKind) int {
+	name := p.tokens[j+1].text
+	last := -1
+	if kind == DeclType {
+		last = p.skipStatement(j, end) - 1
+	} else {
+		k := j + 2
+		for k < end && !p.is(k, "{") && !p.is(k, ";") {
+			if p.is(k, "<") {
+				k = p.skipAngles(k, end)
+				continue
+			}
+			k++
+		}
+		if p.is(k, "{") {
+			last = p.closing(k, end)
+		}
+	}
+	if last < j {
+		return -1
+	}
+	p.addDecl(name, container, kind, first, last, true)
+	return last + 1
+}
+
+// parseAmbientVariable handles "declare const name: T;" whose keyword is at j
+// and whose modifiers start at first.
+func (p *tsParser) parseAmbientVariable(first, j, end int, container string) int {
+	kind := DeclConst
+	if !p.is(j, "const") {
+		kind = DeclVar
+	}
+	last := p.skipStatement(j, end) - 1
+	p.addDecl(p.tokens[j+1].text, container, kind, first, last, true)
+	return last + 1
+}
+
+// skipBindingType skips the ": Type" annotation of a variable at i, such as
+// ": React.FC<Props>" or ": (req: Request) => void", and returns the index of
+// the "=" after it, or -1 when the variable has no initializer.
+func (p *tsParser) skipBindingType(i, end int) int {
+	for j := i + 1; j < end; j++ {
+		switch {
+		case p.is(j, "="):
+			return j
+		case p.is(j, ";") || p.endsStatement(j):
+			return -1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		case p.is(j, "<"):
+			j = p.skipAngles(j, end) - 1
+		}
+	}
+	return -1
+}
+
+// parseVariable handles "const name: T = (...): R => { ... }", function
+// expressions and expression-bodied arrows as well as classes and object
+// literals assigned to a variable. The keyword is at j and the modifiers start
+// at first.
+func (p *tsParser) parseVariable(first, j, end int, container string) int {
+	k := j + 1
+	if !p.isIdent(k) {
+		return -1
+	}
+	name := p.tokens[k].text
+	k++
+	if p.is(k, ":") {
+		k = p.skipBindingType(k, end)
+	}
+	if !p.is(k, "=") {
+		return -1
+	}
+	k++
+
+	switch {
+	case p.is(k, "class"):
+		return p.parseClass(-1, k, end, container, name)
+	case p.is(k, "{"):
+		return p.parseObjectBlock(first, k, end, container, name)
+	}
+
+	last := p.functionExpression(k, end)
+	if last == -1 {
+		return -1
+	}
+	p.addDecl(name, container, DeclFunc, first, last, true)
+	return last + 1
+}
+
+// functionExpression expects a function expression or an arrow function at k,
+// such as "async function (req) { ... }", "x => { ... }" or
+// "(a, b): number => a + b". It returns the index of its last token including
+// a trailing ";", or -1 when tokens[k:] is not one.
+func (p *tsParser) functionExpression(k, end int) int {
+	if p.is(k, "async") && k+1 < end && !p.tokens[k+1].newlineBefore && !p.is(k+1, "=>") {
+		k++
+	}
+	if p.is(k, "function") {
+		k++
+		if p.is(k, "*") {
+			k++
+		}
+		if p.isIdent(k) {
+			k++
+		}
+		bodyEnd := p.functionBody(k, end, false)
+		if bodyEnd == -1 {
+			return -1
+		}
+		return p.withSemicolon(bodyEnd, end)
+	}
+
+	if p.isIdent(k) && p.is(k+1, "=>") {
+		k++
+	} else {
+		if p.is(k, "<") {
+			k = p.skipAngles(k, end)
+		}
+		if !p.is(k, "(") {
+			return -1
+		}
+		k = p.skipTypeAnnotation(p.closing(k, end)+1, end, true)
+		if !p.is(k, "=>") {
+			return -1
+		}
+	}
+	switch {
+	case k+1 >= end:
+		return -1
+	case p.is(k+1, "{"):
+		return p.withSemicolon(p.closing(k+1, end), end)
+	}
+	// An expression body runs to the end of the statement
+	return p.skipStatement(k, end) - 1
+}
+
+// parseNamespace handles "namespace A.B { ... }", "declare module 'x' { ... }"
+// and "declare global { ... }" whose keyword is at j and whose modifiers start
+// at first. The block is recorded as a whole and its statements are walked
+// with the namespace as container; a module is named after its specifier
+// without quotes.
+func (p *tsParser) parseNamespace(first, j, end int, container string) int {
+	k := j + 1
+	name := ""
+	switch {
+	case p.is(j, "global"):
+		name = "global"
+	case k < end && p.tokens[k].kind == tsString && len(p.tokens[k].text) >= 2:
+		name = p.tokens[k].text[1 : len(p.tokens[k].text)-1]
+		k++
+	default:
+		for p.isIdent(k) {
+			name = qualify(name, p.tokens[k].text)
+			if !p.is(k+1, ".") {
+				k++
+				break
+			}
+			k += 2
+		}
+	}
+	if name == "" || !p.is(k, "{") {
+		return -1
+	}
+	closeIdx := p.closing(k, end)
+	p.addDecl(name, container, DeclNamespace, first, closeIdx, true)
+	p.parseStatements(k+1, closeIdx, qualify(container, name))
+	return closeIdx + 1
+}
+
+// parseClass handles a class whose keyword is at j and walks its members. A
+// class declaration, whose modifiers start at first, is recorded as a whole
+// too; class expressions pass -1 and are named after the variable they are
+// assigned to. A non-empty name replaces the declared one. It returns the index after the class body.
+func (p *tsParser) parseClass(first, j, end int, container, name string) int {
+	k := j + 1
+	if p.isIdent(k) && !p.is(k, "extends") && !p.is(k, "implements") {
+		if name == "" {
+			name = p.tokens[k].text
+		}
+		k++
+	}
+	for k < end && !p.is(k, "{") {
+		switch {
+		case p.is(k, "<"):
+			k = p.skipAngles(k, end)
+			continue
+		case p.is(k, "(") || p.is(k, "["):
+			k = p.closing(k, end)
+		case p.is(k, ";"):
+			return -1
+		}
+		k++
+	}
+	if k >= end {
+		return -1
+	}
+	bodyEnd := p.closing(k, end)
+	if first != -1 && name != "" {
+		p.addDecl(name, container, DeclClass, first, bodyEnd, true)
+	}
+	p.parseClassBody(k+1, bodyEnd, qualify(container, name))
+	return bodyEnd + 1
+}
+
+// tsMemberModifiers may precede a class member name.
+var tsMemberModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "static": true, "async": true,
+	"readonly": true, "override": true, "abstract": true, "declare": true, "accessor": true,
+}
+
+// parseClassBody walks the members in tokens[from:to] and records methods
+// with their decorators, accessors and abstract or overload signatures.
+// Constructors are not synced.
+func (p *tsParser) parseClassBody(from, to int, container string) {
+	for i := from; i < to; {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+		k := p.skipDecorators(i, to)
+		// A modifier followed by "(" is the member's name, e.g. a method "static()"
+		for k < to && p.isIdent(k) && tsMemberModifiers[p.tokens[k].text] && !p.is(k+1, "(") && !p.is(k+1, "<") {
+			k++
+		}
+		prefix, k := p.accessorPrefix(k)
+		if name, m, ok := p.memberName(k, to); ok {
+			if p.is(m, "?") || p.is(m, "!") {
+				m++
+			}
+			if last, hasBody := p.signature(m, to, false); last != -1 {
+				if name != "constructor" {
+					p.addDecl(prefix+name, container, DeclFunc, i, last, hasBody)
+				}
+				i = last + 1
+				continue
+			}
+		}
+		i = p.skipStatement(i, to)
+	}
+}
+
+// parseObjectBlock records the object literal whose "{" is at k as a block
+// that starts at first, along with a ";" after it, and walks its properties.
+// It returns the index after the block.
+func (p *tsParser) parseObjectBlock(first, k, end int, container, name string) int {
+	closeIdx := p.closing(k, end)
+	last := closeIdx
+	if p.is(last+1, ";") {
+		last++
+	}
+	p.addDecl(name, container, DeclObject, first, last, true)
+	p.parseObjectLiteral(k+1, closeIdx, qualify(container, name))
+	return last + 1
+}
+
+// parseObjectLiteral walks the properties in tokens[from:to] and records
+// method shorthands and properties holding functions. Nested object literals
+// extend the container path.
+func (p *tsParser) parseObjectLiteral(from, to int, container string) {
+	for i := from; i < to; {
+		if p.is(i, ",") {
+			i++
+			continue
+		}
+		k := i
+		if p.is(k, "async") && !p.is(k+1, "(") && !p.is(k+1, ":") && !p.is(k+1, ",") {
+			k++
+		}
+		prefix, k := p.accessorPrefix(k)
+		if name, m, ok := p.memberName(k, to); ok {
+			if bodyEnd := p.functionBody(m, to, false); bodyEnd != -1 {
+				p.addDecl(prefix+name, container, DeclFunc, i, bodyEnd, true)
+				i = bodyEnd + 1
+				continue
+			}
+			if prefix == "" && p.is(m, ":") {
+				v := m + 1
+				if p.is(v, "{") {
+					i = p.parseObjectBlock(i, v, to, container, name)
+					continue
+				}
+				// A function value, expression-bodied arrows included, ends
+				// at the "," after the property
+				propEnd := p.skipProperty(v, to)
+				if p.is(propEnd-1, ",") {
+					propEnd--
+				}
+				if bodyEnd := p.functionExpression(v, propEnd); bodyEnd != -1 {
+					p.addDecl(name, container, DeclFunc, i, bodyEnd, true)
+					i = bodyEnd + 1
+					continue
+				}
+			}
+		}
+		i = p.skipProperty(i, to)
+	}
+}
+
+// skipProperty returns the index just past the "," ending the object literal
+// property at i.
+func (p *tsParser) skipProperty(i, end int) int {
+	for j := i; j < end; j++ {
+		switch {
+		case p.is(j, ","):
+			return j + 1
+		case p.isOpen(j):
+			j = p.closing(j, end)
+		}
+	}
+	return end
+}
+
+// typeScriptLanguage syncs TypeScript and JavaScript sources. The JSX
+// variants also tokenize JSX elements.
+type typeScriptLanguage struct {
+	name       string
+	aliases    []string
+	extensions []string
+	jsx        bool
+}
+
+var (
+	langTypeScript = &typeScriptLanguage{name: "typescript", aliases: []string{"ts"}, extensions: []string{".ts", ".mts", ".cts"}}
+	langTSX        = &typeScriptLanguage{name: "tsx", extensions: []string{".tsx"}, jsx: true}
+	langJavaScript = &typeScriptLanguage{name: "javascript", aliases: []string{"js"}, extensions: []string{".js", ".mjs", ".cjs"}}
+	langJSX        = &typeScriptLanguage{name: "jsx", extensions: []string{".jsx"}, jsx: true}
+)
+
+func (l *typeScriptLanguage) Name() string         { return l.name }
+func (l *typeScriptLanguage) Family() string       { return "TypeScript" }
+func (l *typeScriptLanguage) Aliases() []string    { return l.aliases }
+func (l *typeScriptLanguage) Extensions() []string { return l.extensions }
+
+// tsIndicators are the snippets that make content look like TypeScript.
+var tsIndicators = []string{"function ", "const ", "export ", "interface ", "import ", "class ", "=>", "async function", "public ", "private ", ": void", ": string", ": number", ": boolean", "<T>"}
+
+func (l *typeScriptLanguage) ContentScore(content string) int {
+	return countIndicators(content, tsIndicators)
+}
+
+func (l *typeScriptLanguage) Extract(content string) ([]Function, error) {
+	return extractTypeScriptDeclarations(content, l.jsx), nil
+}
+
+// Key qualifies members with their container, e.g. "Class.method".
+func (l *typeScriptLanguage) Key(fn Function) string {
+	return qualify(fn.Container, fn.Name)
+}
+
+// Finish adds the imports the synced entries need.
+func (l *typeScriptLanguage) Finish(fr *FunctionReplacer, content, sourceContent string, synced []Function) (string, error) {
+	return l.mergeImports(content, sourceContent, synced), nil
+}
+
+// Insert places the new members of classes, namespaces and object literals
+// that exist in the target before the closing brace of that block, after its
+// last member, and drops the members of blocks that are added as a whole. It
+// returns the insertion edits and the entries that are still to be appended to
+// the file.
+func (*typeScriptLanguage) Insert(targetContent string, targetFunctions, sourceFunctions, newFunctions []Function) ([]textEdit, []Function) {
+	insertions, rest := blockMemberInsertions(targetFunctions, sourceFunctions, newFunctions)
+	var edits []textEdit
+	var braced []memberInsertion
+	for _, ins := range insertions {
+		if ins.block.Kind == DeclObject {
+			edits = append(edits, objectMemberEdit(targetContent, ins))
+		} else {
+			braced = append(braced, ins)
+		}
+	}
+	return append(edits, braceMemberEdits(targetContent, braced)...), rest
+}
+
+// objectMemberEdit places new properties before the closing brace of an
+// object literal. Properties are separated by commas; a trailing comma after
+// the last one is kept and added to the new last one. The closing brace of a
+// one-line object moves to a line of its own.
+func objectMemberEdit(targetContent string, ins memberInsertion) textEdit {
+	closeBrace := ins.block.StartPos + strings.LastIndexByte(ins.block.FullText, '}')
+	last := len(strings.TrimRight(targetContent[:closeBrace], " \t\r\n"))
+	text := strings.Join(ins.members, ",\n\n")
+	closing := targetContent[last:closeBrace]
+	if !strings.Contains(closing, "\n") {
+		closing = "\n" + leadingSpace(ins.block.Indent)
+	}
+	switch targetContent[last-1] {
+	case '{':
+		return textEdit{start: last, end: closeBrace, text: "\n" + text + closing}
+	case ',':
+		return textEdit{start: last, end: closeBrace, text: "\n\n" + text + "," + closing}
+	default:
+		return textEdit{start: last, end: closeBrace, text: ",\n\n" + text + closing}
+	}
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTypeScriptDeclarations_Scopes(t *testing.T) {
+	content, err := readFile("testdata/ts_scopes.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractTypeScriptDeclarations(content, false)
+
+	expected := []string{"outer", "typed", "Service", "load", "create", "last"}
+	if len(declarations) != len(expected) {
+		t.Fatalf("Ожидалось %d объявлений, получено %d: %+v", len(expected), len(declarations), declarations)
+	}
+	for i, decl := range declarations {
+		if decl.Name != expected[i] {
+			t.Errorf("Ожидалось имя [%d] %s, получено %s", i, expected[i], decl.Name)
+		}
+		if got := content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+			t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+		}
+	}
+
+	if last := declarations[len(declarations)-1].FullText; last != "function last() {\n    return 42;\n}" {
+		t.Errorf("Неверный текст функции last:\n%s", last)
+	}
+	if typed := declarations[1].FullText; typed[len(typed)-2:] != "};" {
+		t.Errorf("Стрелочная функция должна включать завершающую ';':\n%s", typed)
+	}
+}
+
+func TestTokenizeTypeScript_Literals(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Regex after assignment",
+			input:    `x = /{[/]}/gi;`,
+			expected: []string{"x", "=", `/{[/]}/gi`, ";"},
+		},
+		{
+			name:     "Division after identifier",
+			input:    `a / b / c`,
+			expected: []string{"a", "/", "b", "/", "c"},
+		},
+		{
+			name:     "Strings, templates and comments",
+			input:    "f('}', \"{\", `}` /* { */) // }",
+			expected: []string{"f", "(", "'}'", ",", `"{"`, ",", "`}`", ")"},
+		},
+		{
+			name:     "Nested template literals",
+			input:    "x = `a ${ `b ${c + \"}\"} {` } d ${ {e: 1}.e }`;",
+			expected: []string{"x", "=", "`a ${ `b ${c + \"}\"} {` } d ${ {e: 1}.e }`", ";"},
+		},
+		{
+			name:     "Arrow and spread",
+			input:    `(...args) => {}`,
+			expected: []string{"(", "...", "args", ")", "=>", "{", "}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tokenizeTypeScript(tt.input, false)
+			var got []string
+			for _, tok := range tokens {
+				got = append(got, tok.text)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Ожидались токены %q, получено %q", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Токен [%d]: ожидалось %q, получено %q", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTypeScriptDeclarations_Containers(t *testing.T) {
+	content, err := readFile("testdata/ts_containers_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	var keys []string
+	for _, decl := range extractTypeScriptDeclarations(content, false) {
+		keys = append(keys, langTypeScript.Key(decl))
+	}
+
+	expected := []string{"Header", "Header.render", "Footer", "Footer.render", "api", "api.users", "api.users.list", "api.ping", "format", "Util", "Util.format"}
+	if strings.Join(keys, " ") != strings.Join(expected, " ") {
+		t.Errorf("Ожидались ключи %v, получено %v", expected, keys)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptContainers(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_containers_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_containers_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_containers_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_containers_target.ts: %v", err)
+	}
+
+	sourceFunctions, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langTypeScript)
+
+	shouldContain := []string{
+		"<header>\"",
+		"<footer> from source",
+		"/users?source",
+		"pong from source",
+		"top-level from target",
+		"${value} from source",
+	}
+	for _, want := range shouldContain {
+		if !strings.Contains(result, want) {
+			t.Errorf("Не найдена ожидаемая строка '%s'. Результат:\n%s", want, result)
+		}
+	}
+	for _, unwanted := range []string{"<header> from target", "<footer>\"", "pong\""} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("Найдена нежелательная строка '%s'", unwanted)
+		}
+	}
+}
+
+func TestExtractTypeScriptDeclarations_Members(t *testing.T) {
+	content, err := readFile("testdata/ts_members_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractTypeScriptDeclarations(content, false)
+	var keys []string
+	for _, decl := range declarations {
+		keys = append(keys, langTypeScript.Key(decl))
+		if got := content[decl.StartPos:decl.EndPos]; got != decl.FullText {
+			t.Errorf("Диапазон %d-%d объявления %s не совпадает с FullText:\n%s", decl.StartPos, decl.EndPos, decl.Name, got)
+		}
+	}
+
+	expected := []string{"Shape", "Shape.area", "Shape.scale", "Shape.get name", "Shape.set name", "Shape.points", "Shape.#describe", "ItemsController", "ItemsController.findOne", "parse"}
+	if strings.Join(keys, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("Ожидались ключи %v, получено %v", expected, keys)
+	}
+
+	scale := declarations[2]
+	if !strings.HasPrefix(scale.FullText, "/** Scales the shape from source. */\n    scale(factor: number): void;") || !strings.HasSuffix(scale.FullText, "y ?? x);\n    }") {
+		t.Errorf("Перегрузки scale должны идти одним блоком с JSDoc:\n%s", scale.FullText)
+	}
+	findOne := declarations[8]
+	if !strings.HasPrefix(findOne.Doc, "/**\n     * Returns one item.\n     */") || !strings.Contains(findOne.FullText, "@UseGuards(AuthGuard)\n    async findOne(") {
+		t.Errorf("Декораторы и JSDoc должны входить в метод findOne:\n%s", findOne.FullText)
+	}
+	if parse := declarations[9]; !strings.HasPrefix(parse.FullText, "export function parse(value: string): number;\n") {
+		t.Errorf("Перегрузки parse должны идти одним блоком:\n%s", parse.FullText)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptMembers(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_members_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_members_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_members_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_members_target.ts: %v", err)
+	}
+
+	sourceFunctions, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langTypeScript)
+
+	if result != sourceContent {
+		t.Errorf("Результат должен совпасть с исходником.\nОжидалось:\n%s\nПолучено:\n%s", sourceContent, result)
+	}
+}
+
+func TestExtractTypeScriptDeclarations_Types(t *testing.T) {
+	content, err := readFile("testdata/ts_types_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractTypeScriptDeclarations(content, false)
+
+	expected := []struct {
+		name string
+		kind DeclKind
+	}{
+		{"ListProps", DeclInterface},
+		{"State", DeclType},
+		{"Direction", DeclEnum},
+		{"VERSION", DeclConst},
+		{"Handler", DeclType},
+		{"render", DeclFunc},
+	}
+	if len(declarations) != len(expected) {
+		t.Fatalf("Ожидалось %d объявлений, получено %d: %+v", len(expected), len(declarations), declarations)
+	}
+	for i, decl := range declarations {
+		if decl.Name != expected[i].name || decl.Kind != expected[i].kind {
+			t.Errorf("Ожидалось [%d] %s %s, получено %s %s", i, expected[i].kind, expected[i].name, decl.Kind, decl.Name)
+		}
+	}
+	if state := declarations[1].FullText; !strings.HasSuffix(state, `since: number };`) {
+		t.Errorf("Тип State должен заканчиваться на ';':\n%s", state)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptTypes(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_types_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_types_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_types_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_types_target.ts: %v", err)
+	}
+
+	sourceDeclarations, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langTypeScript)
+
+	shouldContain := []string{
+		"/** Props of the list from source. */\nexport interface ListProps<T extends { id: string }> {\n    items: T[];\n    onSelect(item: T): void;\n}",
+		"| { status: \"loading\"; since: number };",
+		"Left = \"LEFT\",",
+		"declare const VERSION: string;",
+		"\ntype Handler = (event: Event) => void;\n",
+	}
+	for _, want := range shouldContain {
+		if !strings.Contains(result, want) {
+			t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "VERSION: number") {
+		t.Error("Объявление VERSION не было заменено")
+	}
+}
+
+func TestExtractTypeScriptDeclarations_JSX(t *testing.T) {
+	content, err := readFile("testdata/ts_component.tsx")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать файл: %v", err)
+	}
+
+	declarations := extractTypeScriptDeclarations(content, true)
+
+	expected := []string{"List", "identity", "Badge", "Button", "useSelection", "fallback", "last"}
+	if len(declarations) != len(expected) {
+		t.Fatalf("Ожидалось %d объявлений, получено %d: %+v", len(expected), len(declarations), declarations)
+	}
+	for i, decl := range declarations {
+		if decl.Name != expected[i] {
+			t.Errorf("Ожидалось имя [%d] %s, получено %s", i, expected[i], decl.Name)
+		}
+	}
+	if list := declarations[0].FullText; !strings.HasSuffix(list, "</ul>\n    );\n}") {
+		t.Errorf("Функция List обрезана:\n%s", list)
+	}
+	if fallback := declarations[5].FullText; !strings.HasSuffix(fallback, "<pre>{message}</pre>;") {
+		t.Errorf("Функция fallback обрезана:\n%s", fallback)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptTemplates(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_templates_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_templates_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_templates_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_templates_target.ts: %v", err)
+	}
+
+	sourceFunctions, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения функций из исходника: %v", err)
+	}
+	var names []string
+	for _, fn := range sourceFunctions {
+		names = append(names, fn.Name)
+	}
+	if strings.Join(names, " ") != "greet nested after" {
+		t.Fatalf("Ожидались функции greet nested after, получено %v", names)
+	}
+
+	result := replacer.replaceFunctions(targetContent, sourceFunctions, langTypeScript)
+	if result != sourceContent {
+		t.Errorf("Результат должен совпасть с исходником.\nОжидалось:\n%s\nПолучено:\n%s", sourceContent, result)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptClassMembers(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_class_members_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_class_members_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_class_members_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_class_members_target.ts: %v", err)
+	}
+
+	sourceDeclarations, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langTypeScript)
+
+	shouldContain := []string{
+		"    increment(): void {\n        this.count++;\n    }\n\n    /** Resets the counter. */\n    reset(): void {\n        this.count = 0;\n    }\n}\n\nclass Empty {",
+		"class Empty {\n  describe(): string {\n    return \"no longer empty\";\n  }\n}\n",
+		"class Settings {\n    theme = \"dark\";\n\n    toggle(): void {\n        this.theme = this.theme === \"dark\" ? \"light\" : \"dark\";\n    }\n}\n",
+		"new Counter().increment();\n}\n\nexport class Logger {\n    constructor(private readonly prefix: string) {}\n",
+	}
+	for _, want := range shouldContain {
+		if !strings.Contains(result, want) {
+			t.Errorf("Не найдена ожидаемая строка:\n%s\nРезультат:\n%s", want, result)
+		}
+	}
+	if strings.Count(result, "log(message: string)") != 1 {
+		t.Errorf("Метод log должен войти только в новый класс Logger:\n%s", result)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptExpressions(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_expressions_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_expressions_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_expressions_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_expressions_target.ts: %v", err)
+	}
+	expected, err := readFile("testdata/ts_expressions_expected.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_expressions_expected.ts: %v", err)
+	}
+
+	sourceDeclarations, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	var keys []string
+	for _, decl := range sourceDeclarations {
+		keys = append(keys, langTypeScript.Key(decl))
+	}
+	expectedKeys := []string{"default", "handler", "add", "twice", "label", "double"}
+	if strings.Join(keys, " ") != strings.Join(expectedKeys, " ") {
+		t.Fatalf("Ожидались ключи %v, получено %v", expectedKeys, keys)
+	}
+
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langTypeScript)
+	if result != expected {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+	}
+}
+
+func TestExtractTypeScriptDeclarations_DefaultExports(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Anonymous function",
+			input:    "export default async function () {\n    return 1;\n}\n",
+			expected: []string{"default"},
+		},
+		{
+			name:     "Arrow with expression body",
+			input:    "export default (props) => props.children;\n",
+			expected: []string{"default"},
+		},
+		{
+			name:     "Named class and its methods",
+			input:    "export default class Store {\n    load() {}\n}\n",
+			expected: []string{"default", "default.load"},
+		},
+		{
+			name:     "Object literal",
+			input:    "export default {\n    methods: {\n        save() {},\n    },\n};\n",
+			expected: []string{"default", "default.methods", "default.methods.save"},
+		},
+		{
+			name:     "Re-export is not a declaration",
+			input:    "export default Store;\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, decl := range extractTypeScriptDeclarations(tt.input, false) {
+				keys = append(keys, langTypeScript.Key(decl))
+			}
+			if strings.Join(keys, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Ожидались ключи %v, получено %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestReplaceFunctions_TypeScriptNamespaces(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_namespaces_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_namespaces_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_namespaces_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_namespaces_target.ts: %v", err)
+	}
+	expected, err := readFile("testdata/ts_namespaces_expected.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_namespaces_expected.ts: %v", err)
+	}
+
+	sourceDeclarations, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	var keys []string
+	for _, decl := range sourceDeclarations {
+		keys = append(keys, langTypeScript.Key(decl))
+	}
+	expectedKeys := []string{"Api", "Api.get", "Api.post", "express", "express.Request", "express.session", "global", "global.Window", "Cache.Keys", "Cache.Keys.user"}
+	if strings.Join(keys, " ") != strings.Join(expectedKeys, " ") {
+		t.Fatalf("Ожидались ключи %v, получено %v", expectedKeys, keys)
+	}
+
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langTypeScript)
+	if result != expected {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+	}
+}
+
+func TestReplaceFunctions_TypeScriptObjectLiterals(t *testing.T) {
+	replacer := NewFunctionReplacer()
+
+	sourceContent, err := readFile("testdata/ts_objects_source.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_objects_source.ts: %v", err)
+	}
+	targetContent, err := readFile("testdata/ts_objects_target.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_objects_target.ts: %v", err)
+	}
+	expected, err := readFile("testdata/ts_objects_expected.ts")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать ts_objects_expected.ts: %v", err)
+	}
+
+	sourceDeclarations, err := langTypeScript.Extract(sourceContent)
+	if err != nil {
+		t.Fatalf("Ошибка извлечения объявлений из исходника: %v", err)
+	}
+	result := replacer.replaceFunctions(targetContent, sourceDeclarations, langTypeScript)
+	if result != expected {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", expected, result)
+	}
+	if again := replacer.replaceFunctions(result, sourceDeclarations, langTypeScript); again != result {
+		t.Errorf("Повторная синхронизация изменила результат:\n%s", again)
+	}
+
+	// A class expression is no block, so its new method is dropped rather
+	// than appended at the top level
+	source := "const Widget = class {\n    render() { return 1; }\n    update() { return 2; }\n};\n"
+	target := "const Widget = class {\n    render() { return 0; }\n};\n"
+	declarations, _ := langTypeScript.Extract(source)
+	want := "const Widget = class {\n    render() { return 1; }\n};\n"
+	if got := replacer.replaceFunctions(target, declarations, langTypeScript); got != want {
+		t.Errorf("Ожидалось:\n%s\nполучено:\n%s", want, got)
+	}
+}